@@ -11,11 +11,14 @@ import (
 	"gopkg.in/alecthomas/kingpin.v2"
 	restclient "k8s.io/client-go/rest"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
 )
 
 const (
 	defaultAddress        = ":8443"
 	defaultCiliumCidr     = "192.168.0.0/16"
+	defaultCiliumCidrSize = "16"
 	defaultMetricsAddress = ":8080"
 )
 
@@ -26,11 +29,16 @@ type Config struct {
 	AvailabilityZones        string
 	CertFile                 string
 	CiliumDefaultPodCidr     string
+	CiliumPodCidrSupernet    string
+	CiliumPodCidrSize        int
 	DockerCIDR               string
 	Endpoint                 string
+	FluxServiceAccount       string
 	IPAMNetworkCIDR          string
 	KubernetesClusterIPRange string
 	MasterInstanceTypes      string
+	Policy                   aws.Policy
+	PolicyFile               string
 	PodCIDR                  string
 	PodSubnet                string
 	Region                   string
@@ -85,12 +93,16 @@ func Parse() (Config, error) {
 	kingpin.Flag("admin-group", "Tenant Admin Target Group").Required().StringVar(&config.AdminGroup)
 	kingpin.Flag("availability-zones", "List of AWS availability zones").Required().StringVar(&config.AvailabilityZones)
 	kingpin.Flag("default-cilium-pod-cidr", "Default CIDR to use for Pods with Cilium").Default(defaultCiliumCidr).StringVar(&config.CiliumDefaultPodCidr)
+	kingpin.Flag("cilium-pod-cidr-supernet", "CIDR from which Cilium pod CIDRs are allocated when upgrading to Cilium, disabled when empty").Default("").StringVar(&config.CiliumPodCidrSupernet)
+	kingpin.Flag("cilium-pod-cidr-size", "Prefix length of the Cilium pod CIDRs allocated from the supernet").Default(defaultCiliumCidrSize).IntVar(&config.CiliumPodCidrSize)
 	kingpin.Flag("docker-cidr", "Default CIDR from Docker").Required().StringVar(&config.DockerCIDR)
 	kingpin.Flag("endpoint", "Default kubernetes endpoint").Required().StringVar(&config.Endpoint)
+	kingpin.Flag("flux-service-account", "User name of the Flux service account allowed to change Flux-managed objects, out-of-band edits are allowed when empty").Default("").StringVar(&config.FluxServiceAccount)
 	kingpin.Flag("ipam-network-cidr", "Default CIDR from tenant cluster").Required().StringVar(&config.IPAMNetworkCIDR)
 	kingpin.Flag("kubernetes-cluster-ip-range", "Default CIDR from Kubernetes").Required().StringVar(&config.KubernetesClusterIPRange)
 	kingpin.Flag("master-instance-types", "List of AWS master instance types").Required().StringVar(&config.MasterInstanceTypes)
 	kingpin.Flag("metrics-address", "The metrics address for Prometheus").Default(defaultMetricsAddress).StringVar(&config.MetricsAddress)
+	kingpin.Flag("policy-file", "YAML file configuring the gitops guard, immutability policy, instance type policy, release graph, restricted identities and scaling policy").Default("").StringVar(&config.PolicyFile)
 	kingpin.Flag("pod-cidr", "Default pod CIDR").Required().StringVar(&config.PodCIDR)
 	kingpin.Flag("pod-subnet", "Default pod subnet").Required().StringVar(&config.PodSubnet)
 	kingpin.Flag("region", "Default cluster region").Required().StringVar(&config.Region)
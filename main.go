@@ -14,11 +14,14 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/giantswarm/aws-admission-controller/v4/config"
+	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
 	awscluster "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3/awscluster"
 	awscontrolplane "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3/awscontrolplane"
 	awsmachinedeployment "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3/awsmachinedeployment"
 	cluster "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3/cluster"
 	g8scontrolplane "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3/g8scontrolplane"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3/gitops"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3/immutability"
 	machinedeployment "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3/machinedeployment"
 	networkpool "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3/networkpool"
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/mutator"
@@ -31,6 +34,12 @@ func main() {
 		panic(microerror.JSON(err))
 	}
 
+	// The policy file is shared by several admitters and loaded once.
+	config.Policy, err = aws.LoadPolicy(config.PolicyFile, config.AdminGroup)
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+
 	// Setup handler for mutating webhook
 	awsclusterMutator, err := awscluster.NewMutator(config)
 	if err != nil {
@@ -98,6 +107,18 @@ func main() {
 		panic(microerror.JSON(err))
 	}
 
+	// Shared by the validators of all Flux-managed kinds.
+	gitopsValidator, err := gitops.NewValidator(config)
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+
+	// Shared by the validators of all kinds.
+	immutabilityValidator, err := immutability.NewValidator(config)
+	if err != nil {
+		panic(microerror.JSON(err))
+	}
+
 	// Here we register our endpoints.
 	handler := http.NewServeMux()
 
@@ -108,13 +129,13 @@ func main() {
 	handler.Handle("/mutate/v1beta1/cluster", mutator.Handler(clusterMutator))
 	handler.Handle("/mutate/v1alpha3/g8scontrolplane", mutator.Handler(g8scontrolplaneMutator))
 	handler.Handle("/mutate/v1beta1/machinedeployment", mutator.Handler(machinedeploymentMutator))
-	handler.Handle("/validate/v1alpha3/awscluster", validator.Handler(awsclusterValidator))
-	handler.Handle("/validate/v1alpha3/awscontrolplane", validator.Handler(awscontrolplaneValidator))
-	handler.Handle("/validate/v1alpha3/awsmachinedeployment", validator.Handler(awsmachinedeploymentValidator))
-	handler.Handle("/validate/v1beta1/cluster", validator.Handler(clusterValidator))
-	handler.Handle("/validate/v1alpha3/g8scontrolplane", validator.Handler(g8scontrolplaneValidator))
-	handler.Handle("/validate/v1beta1/machinedeployment", validator.Handler(machinedeploymentValidator))
-	handler.Handle("/validate/v1alpha3/networkpool", validator.Handler(networkPoolValidator))
+	handler.Handle("/validate/v1alpha3/awscluster", validator.Handler(awsclusterValidator, immutabilityValidator, gitopsValidator))
+	handler.Handle("/validate/v1alpha3/awscontrolplane", validator.Handler(awscontrolplaneValidator, immutabilityValidator, gitopsValidator))
+	handler.Handle("/validate/v1alpha3/awsmachinedeployment", validator.Handler(awsmachinedeploymentValidator, immutabilityValidator, gitopsValidator))
+	handler.Handle("/validate/v1beta1/cluster", validator.Handler(clusterValidator, immutabilityValidator, gitopsValidator))
+	handler.Handle("/validate/v1alpha3/g8scontrolplane", validator.Handler(g8scontrolplaneValidator, immutabilityValidator, gitopsValidator))
+	handler.Handle("/validate/v1beta1/machinedeployment", validator.Handler(machinedeploymentValidator, immutabilityValidator, gitopsValidator))
+	handler.Handle("/validate/v1alpha3/networkpool", validator.Handler(networkPoolValidator, immutabilityValidator, gitopsValidator))
 
 	handler.HandleFunc("/healthz", healthCheck)
 	metrics := http.NewServeMux()
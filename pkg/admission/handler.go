@@ -0,0 +1,341 @@
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/giantswarm/microerror"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/handler"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/metrics"
+)
+
+const (
+	// Mutating is the webhook label of handlers running mutation stages.
+	Mutating = "mutating"
+	// Validating is the webhook label of handlers running only validation stages.
+	Validating = "validating"
+
+	// DefaultTimeout is the time the stages of a handler may take before the
+	// request is answered with an error. It stays below the default webhook
+	// timeout of the Kubernetes API server.
+	DefaultTimeout = 8 * time.Second
+)
+
+var (
+	scheme       = runtime.NewScheme()
+	codecs       = serializer.NewCodecFactory(scheme)
+	Deserializer = codecs.UniversalDeserializer()
+)
+
+// Mutator is an admission stage which computes JSON patches for a request.
+type Mutator interface {
+	Mutate(request *admissionv1.AdmissionRequest) ([]PatchOperation, error)
+}
+
+// Validator is an admission stage which decides whether a request is allowed.
+type Validator interface {
+	Validate(request *admissionv1.AdmissionRequest) (bool, error)
+}
+
+type Config struct {
+	// GroupVersionKinds are the kinds of objects the handler accepts.
+	GroupVersionKinds []schema.GroupVersionKind
+	Log               func(keyVals ...interface{})
+	// Mutators run in order and their patches are concatenated.
+	Mutators []Mutator
+	Resource string
+	// Timeout defaults to DefaultTimeout.
+	Timeout time.Duration
+	// Validators run in order against the object with all patches applied.
+	// The first one to deny the request decides the response.
+	Validators []Validator
+	// Webhook is the webhook label of the metrics, usually Mutating or
+	// Validating.
+	Webhook string
+}
+
+// Handler decodes AdmissionReviews once, runs the configured stages and
+// writes the AdmissionReview response.
+type Handler struct {
+	gvks       []schema.GroupVersionKind
+	log        func(keyVals ...interface{})
+	mutators   []Mutator
+	resource   string
+	timeout    time.Duration
+	validators []Validator
+	webhook    string
+}
+
+func New(config Config) (*Handler, error) {
+	if config.Log == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Log must not be empty", config)
+	}
+	if len(config.GroupVersionKinds) == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.GroupVersionKinds must not be empty", config)
+	}
+	if len(config.Mutators) == 0 && len(config.Validators) == 0 {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Mutators or %T.Validators must not be empty", config, config)
+	}
+	if config.Resource == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Resource must not be empty", config)
+	}
+	if config.Webhook == "" {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Webhook must not be empty", config)
+	}
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	h := &Handler{
+		gvks:       config.GroupVersionKinds,
+		log:        config.Log,
+		mutators:   config.Mutators,
+		resource:   config.Resource,
+		timeout:    config.Timeout,
+		validators: config.Validators,
+		webhook:    config.Webhook,
+	}
+
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	handler.Recover(h.log, h.webhook, h.resource, h.serve)(writer, request)
+}
+
+func (h *Handler) serve(writer http.ResponseWriter, request *http.Request) {
+	start := time.Now()
+	defer func() {
+		metrics.DurationRequests.WithLabelValues(h.webhook, h.resource).Observe(float64(time.Since(start)) / float64(time.Second))
+	}()
+
+	metrics.TotalRequests.WithLabelValues(h.webhook, h.resource).Inc()
+	if request.Method != http.MethodPost {
+		h.log("level", "error", "message", fmt.Sprintf("invalid method: %s", request.Method))
+		metrics.InvalidRequests.WithLabelValues(h.webhook, h.resource).Inc()
+		writer.Header().Set("Allow", http.MethodPost)
+		writer.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if request.Header.Get("Content-Type") != "application/json" {
+		h.log("level", "error", "message", fmt.Sprintf("invalid content-type: %s", request.Header.Get("Content-Type")))
+		metrics.InvalidRequests.WithLabelValues(h.webhook, h.resource).Inc()
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	data, err := handler.ReadBody(request)
+	if handler.IsRequestTooLarge(err) {
+		h.log("level", "error", "message", err.Error())
+		metrics.InvalidRequests.WithLabelValues(h.webhook, h.resource).Inc()
+		writer.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	} else if err != nil {
+		h.log("level", "error", "message", "unable to read request")
+		metrics.InternalError.WithLabelValues(h.webhook, h.resource).Inc()
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	review := admissionv1.AdmissionReview{}
+	if _, _, err := Deserializer.Decode(data, nil, &review); err != nil {
+		h.log("level", "error", "message", "unable to parse admission review request")
+		metrics.InvalidRequests.WithLabelValues(h.webhook, h.resource).Inc()
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err := handler.ValidateReviewVersion(review); err != nil {
+		h.log("level", "error", "message", err.Error())
+		metrics.InvalidRequests.WithLabelValues(h.webhook, h.resource).Inc()
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		h.log("level", "error", "message", "admission review does not contain a request")
+		metrics.InvalidRequests.WithLabelValues(h.webhook, h.resource).Inc()
+		writer.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	resourceName := fmt.Sprintf("%s %s/%s", review.Request.Kind, review.Request.Namespace, handler.ExtractName(review.Request, Deserializer))
+
+	if err := handler.ValidateGroupVersionKind(review.Request, h.gvks); err != nil {
+		h.log("level", "error", "message", fmt.Sprintf("rejecting misrouted request for %s: %v", resourceName, err))
+		h.writeResponse(writer, errorResponse(review.Request.UID, err))
+		metrics.MisroutedRequests.WithLabelValues(h.webhook, h.resource).Inc()
+		return
+	}
+
+	h.writeResponse(writer, h.admitWithTimeout(review.Request, resourceName))
+}
+
+// admitWithTimeout runs the stages in a separate goroutine so that slow
+// stages, e.g. waiting for the Kubernetes API, do not outlive the timeout.
+func (h *Handler) admitWithTimeout(request *admissionv1.AdmissionRequest, resourceName string) *admissionv1.AdmissionResponse {
+	responses := make(chan *admissionv1.AdmissionResponse, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				h.log("level", "error", "message", fmt.Sprintf("recovered from panic while admitting %s: %v", resourceName, r), "stack", string(debug.Stack()))
+				metrics.InternalError.WithLabelValues(h.webhook, h.resource).Inc()
+				metrics.RecoveredPanics.WithLabelValues(h.webhook, h.resource).Inc()
+				responses <- internalErrorResponse(request.UID)
+			}
+		}()
+		responses <- h.admit(request, resourceName)
+	}()
+
+	timer := time.NewTimer(h.timeout)
+	defer timer.Stop()
+
+	select {
+	case response := <-responses:
+		return response
+	case <-timer.C:
+		err := microerror.Maskf(timeoutError, "admission of %s did not finish within %s", resourceName, h.timeout)
+		h.log("level", "error", "message", err.Error())
+		metrics.InternalError.WithLabelValues(h.webhook, h.resource).Inc()
+		return internalErrorResponse(request.UID)
+	}
+}
+
+func (h *Handler) admit(request *admissionv1.AdmissionRequest, resourceName string) *admissionv1.AdmissionResponse {
+	var err error
+
+	var patch []PatchOperation
+	for _, m := range h.mutators {
+		p, err := m.Mutate(request)
+		if err != nil {
+			h.log("level", "error", "message", fmt.Sprintf("error during mutation process of %s: %v", resourceName, err))
+			metrics.RejectedRequests.WithLabelValues(h.webhook, h.resource).Inc()
+			return errorResponse(request.UID, microerror.Mask(err))
+		}
+		patch = append(patch, p...)
+	}
+
+	var patchData []byte
+	if len(patch) > 0 {
+		patchData, err = json.Marshal(patch)
+		if err != nil {
+			h.log("level", "error", "message", fmt.Sprintf("unable to serialize patch for %s: %v", resourceName, err))
+			metrics.InternalError.WithLabelValues(h.webhook, h.resource).Inc()
+			return internalErrorResponse(request.UID)
+		}
+	}
+
+	if len(h.validators) > 0 {
+		validationRequest := request
+		if len(patchData) > 0 {
+			validationRequest, err = applyPatch(request, patchData)
+			if err != nil {
+				h.log("level", "error", "message", fmt.Sprintf("unable to apply patch for %s: %v", resourceName, err))
+				metrics.InternalError.WithLabelValues(h.webhook, h.resource).Inc()
+				return internalErrorResponse(request.UID)
+			}
+		}
+
+		for _, v := range h.validators {
+			allowed, err := v.Validate(validationRequest)
+			if err != nil {
+				h.log("level", "error", "message", fmt.Sprintf("error during validation process of %s: %v", resourceName, err))
+				metrics.RejectedRequests.WithLabelValues(h.webhook, h.resource).Inc()
+				return errorResponse(request.UID, microerror.Mask(err))
+			}
+			if !allowed {
+				h.log("level", "debug", "message", fmt.Sprintf("%s webhook denied %s", h.webhook, resourceName))
+				metrics.RejectedRequests.WithLabelValues(h.webhook, h.resource).Inc()
+				return &admissionv1.AdmissionResponse{
+					Allowed: false,
+					UID:     request.UID,
+				}
+			}
+		}
+	}
+
+	h.log("level", "debug", "message", fmt.Sprintf("%s webhook admitted %s (with %d patches)", h.webhook, resourceName, len(patch)))
+	metrics.SuccessfulRequests.WithLabelValues(h.webhook, h.resource).Inc()
+
+	response := &admissionv1.AdmissionResponse{
+		Allowed: true,
+		UID:     request.UID,
+	}
+	if len(patchData) > 0 {
+		pt := admissionv1.PatchTypeJSONPatch
+		response.Patch = patchData
+		response.PatchType = &pt
+	}
+	return response
+}
+
+func (h *Handler) writeResponse(writer http.ResponseWriter, response *admissionv1.AdmissionResponse) {
+	resp, err := json.Marshal(admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "AdmissionReview",
+			APIVersion: handler.AdmissionReviewAPIVersion,
+		},
+		Response: response,
+	})
+	if err != nil {
+		h.log("level", "error", "message", "unable to serialize response", microerror.JSON(err))
+		metrics.InternalError.WithLabelValues(h.webhook, h.resource).Inc()
+		writer.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	if _, err := writer.Write(resp); err != nil {
+		h.log("level", "error", "message", "unable to write response", microerror.JSON(err))
+	}
+}
+
+// applyPatch returns a copy of the request whose object has the patch applied
+// so that validation sees the object as it will be persisted.
+func applyPatch(request *admissionv1.AdmissionRequest, patchData []byte) (*admissionv1.AdmissionRequest, error) {
+	patch, err := jsonpatch.DecodePatch(patchData)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	raw, err := patch.Apply(request.Object.Raw)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	patched := request.DeepCopy()
+	patched.Object = runtime.RawExtension{Raw: raw}
+	return patched, nil
+}
+
+func errorResponse(uid types.UID, err error) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		UID:     uid,
+		Result: &metav1.Status{
+			Reason:  metav1.StatusReasonBadRequest,
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		},
+	}
+}
+
+func internalErrorResponse(uid types.UID) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		Allowed: false,
+		UID:     uid,
+		Result: &metav1.Status{
+			Reason:  metav1.StatusReasonInternalError,
+			Code:    http.StatusInternalServerError,
+			Message: "internal admission controller error",
+		},
+	}
+}
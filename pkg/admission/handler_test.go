@@ -0,0 +1,213 @@
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/giantswarm/microerror"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var testGVK = schema.GroupVersionKind{Group: "infrastructure.giantswarm.io", Version: "v1alpha3", Kind: "AWSCluster"}
+
+type mutateFunc func(request *admissionv1.AdmissionRequest) ([]PatchOperation, error)
+
+func (f mutateFunc) Mutate(request *admissionv1.AdmissionRequest) ([]PatchOperation, error) {
+	return f(request)
+}
+
+type validateFunc func(request *admissionv1.AdmissionRequest) (bool, error)
+
+func (f validateFunc) Validate(request *admissionv1.AdmissionRequest) (bool, error) {
+	return f(request)
+}
+
+func TestHandler(t *testing.T) {
+	labelPatch := mutateFunc(func(request *admissionv1.AdmissionRequest) ([]PatchOperation, error) {
+		return []PatchOperation{PatchAdd("/metadata/labels", map[string]string{"patched": "true"})}, nil
+	})
+	noPatch := mutateFunc(func(request *admissionv1.AdmissionRequest) ([]PatchOperation, error) {
+		return nil, nil
+	})
+	requirePatched := validateFunc(func(request *admissionv1.AdmissionRequest) (bool, error) {
+		obj := metav1.PartialObjectMetadata{}
+		if err := json.Unmarshal(request.Object.Raw, &obj); err != nil {
+			return false, err
+		}
+		if obj.Labels["patched"] != "true" {
+			return false, microerror.Maskf(invalidConfigError, "object was not patched")
+		}
+		return true, nil
+	})
+	deny := validateFunc(func(request *admissionv1.AdmissionRequest) (bool, error) {
+		return false, nil
+	})
+	slow := validateFunc(func(request *admissionv1.AdmissionRequest) (bool, error) {
+		time.Sleep(time.Second)
+		return true, nil
+	})
+	panicking := validateFunc(func(request *admissionv1.AdmissionRequest) (bool, error) {
+		panic("boom")
+	})
+
+	testCases := []struct {
+		name       string
+		mutators   []Mutator
+		validators []Validator
+		method     string
+		kind       metav1.GroupVersionKind
+
+		expectedStatus  int
+		expectedAllowed bool
+		expectedCode    int32
+		expectedPatch   bool
+	}{
+		{
+			// Mutation and validation in one request, validation sees the patched object
+			name:            "case 0",
+			mutators:        []Mutator{labelPatch},
+			validators:      []Validator{requirePatched},
+			expectedStatus:  http.StatusOK,
+			expectedAllowed: true,
+			expectedPatch:   true,
+		},
+		{
+			// Validation only, missing patch is an error with status code
+			name:            "case 1",
+			validators:      []Validator{requirePatched},
+			expectedStatus:  http.StatusOK,
+			expectedAllowed: false,
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			// Mutation without patches does not return an empty patch
+			name:            "case 2",
+			mutators:        []Mutator{noPatch},
+			expectedStatus:  http.StatusOK,
+			expectedAllowed: true,
+		},
+		{
+			// Denying validator
+			name:            "case 3",
+			validators:      []Validator{deny},
+			expectedStatus:  http.StatusOK,
+			expectedAllowed: false,
+		},
+		{
+			// Stages exceeding the timeout
+			name:            "case 4",
+			validators:      []Validator{slow},
+			expectedStatus:  http.StatusOK,
+			expectedAllowed: false,
+			expectedCode:    http.StatusInternalServerError,
+		},
+		{
+			// Panicking stage
+			name:            "case 5",
+			validators:      []Validator{panicking},
+			expectedStatus:  http.StatusOK,
+			expectedAllowed: false,
+			expectedCode:    http.StatusInternalServerError,
+		},
+		{
+			// Misrouted request
+			name:            "case 6",
+			validators:      []Validator{deny},
+			kind:            metav1.GroupVersionKind{Group: "infrastructure.giantswarm.io", Version: "v1alpha3", Kind: "NetworkPool"},
+			expectedStatus:  http.StatusOK,
+			expectedAllowed: false,
+			expectedCode:    http.StatusBadRequest,
+		},
+		{
+			// Wrong method
+			name:           "case 7",
+			validators:     []Validator{deny},
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			h, err := New(Config{
+				GroupVersionKinds: []schema.GroupVersionKind{testGVK},
+				Log:               func(keyVals ...interface{}) {},
+				Mutators:          tc.mutators,
+				Resource:          "test",
+				Timeout:           100 * time.Millisecond,
+				Validators:        tc.validators,
+				Webhook:           Validating,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			kind := metav1.GroupVersionKind(testGVK)
+			if tc.kind.Kind != "" {
+				kind = tc.kind
+			}
+			method := http.MethodPost
+			if tc.method != "" {
+				method = tc.method
+			}
+			body, err := json.Marshal(admissionv1.AdmissionReview{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "AdmissionReview",
+					APIVersion: "admission.k8s.io/v1",
+				},
+				Request: &admissionv1.AdmissionRequest{
+					UID:       "test-uid",
+					Kind:      kind,
+					Operation: admissionv1.Create,
+					Object: runtime.RawExtension{
+						Raw: []byte(`{"apiVersion":"infrastructure.giantswarm.io/v1alpha3","kind":"AWSCluster","metadata":{"name":"test"}}`),
+					},
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			request := httptest.NewRequest(method, "/", bytes.NewReader(body))
+			request.Header.Set("Content-Type", "application/json")
+			recorder := httptest.NewRecorder()
+
+			h.ServeHTTP(recorder, request)
+
+			if recorder.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d but got %d", tc.expectedStatus, recorder.Code)
+			}
+			if recorder.Code != http.StatusOK {
+				return
+			}
+
+			review := admissionv1.AdmissionReview{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &review); err != nil {
+				t.Fatal(err)
+			}
+			if review.Response.UID != "test-uid" {
+				t.Fatalf("expected UID %q but got %q", "test-uid", review.Response.UID)
+			}
+			if review.Response.Allowed != tc.expectedAllowed {
+				t.Fatalf("expected allowed %t but got %t", tc.expectedAllowed, review.Response.Allowed)
+			}
+			var code int32
+			if review.Response.Result != nil {
+				code = review.Response.Result.Code
+			}
+			if code != tc.expectedCode {
+				t.Fatalf("expected code %d but got %d", tc.expectedCode, code)
+			}
+			if (len(review.Response.Patch) > 0) != tc.expectedPatch {
+				t.Fatalf("expected patch %t but got %q", tc.expectedPatch, review.Response.Patch)
+			}
+		})
+	}
+}
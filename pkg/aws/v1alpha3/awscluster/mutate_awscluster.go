@@ -13,6 +13,7 @@ import (
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -93,6 +94,12 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 		return nil, microerror.Maskf(parsingFailedError, "unable to parse AWSCluster: %v", err)
 	}
 
+	patch, err = aws.MutateOrganizationLabel(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, awsCluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	patch, err = m.MutatePodCIDR(*awsCluster)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -102,7 +109,7 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 	patch = aws.MutateCAPILabel(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, awsCluster)
 	result = append(result, patch...)
 
-	patch, err = m.MutateCredential(*awsCluster)
+	patch, err = m.MutateCredential(*awsCluster, aws.Organization(awsCluster, result))
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
@@ -181,7 +188,7 @@ func (m *Mutator) MutateUpdate(request *admissionv1.AdmissionRequest) ([]mutator
 	patch = aws.MutateCAPILabel(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, awsCluster)
 	result = append(result, patch...)
 
-	patch, err = m.MutateCredential(*awsCluster)
+	patch, err = m.MutateCredential(*awsCluster, key.Organization(awsCluster))
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
@@ -219,6 +226,12 @@ func (m *Mutator) MutateUpdate(request *admissionv1.AdmissionRequest) ([]mutator
 		result = append(result, patch...)
 	}
 
+	patch, err = aws.MutateReleasePropagation(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, awsCluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	return result, nil
 }
 
@@ -294,8 +307,9 @@ func (m *Mutator) MutateMasterPreHA(awsCluster infrastructurev1alpha3.AWSCluster
 	return result, nil
 }
 
-// MutateCredential defaults the cluster credential if it is not set.
-func (m *Mutator) MutateCredential(awsCluster infrastructurev1alpha3.AWSCluster) ([]mutator.PatchOperation, error) {
+// MutateCredential defaults the cluster credential of the organization if it
+// is not set.
+func (m *Mutator) MutateCredential(awsCluster infrastructurev1alpha3.AWSCluster, organization string) ([]mutator.PatchOperation, error) {
 	var result []mutator.PatchOperation
 	if awsCluster.Spec.Provider.CredentialSecret.Name != "" && awsCluster.Spec.Provider.CredentialSecret.Namespace != "" {
 		return result, nil
@@ -304,7 +318,7 @@ func (m *Mutator) MutateCredential(awsCluster infrastructurev1alpha3.AWSCluster)
 
 	var secretName types.NamespacedName
 	{
-		secret, err := m.fetchCredentialSecret(key.Organization(&awsCluster))
+		secret, err := m.fetchCredentialSecret(organization)
 		if IsNotFound(err) {
 			// if the credential secret can not be found we do no fail but use the default one
 			m.Log("level", "debug", "message", fmt.Sprintf("Could not fetch credential-secret. Using default secret instead: %v", err))
@@ -492,3 +506,7 @@ func (m *Mutator) Log(keyVals ...interface{}) {
 func (m *Mutator) Resource() string {
 	return "awscluster"
 }
+
+func (m *Mutator) GroupVersionKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{aws.AWSClusterGVK}
+}
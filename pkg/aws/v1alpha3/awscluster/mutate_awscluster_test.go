@@ -10,6 +10,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/key"
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/mutator"
 	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
@@ -134,7 +135,7 @@ func TestAWSClusterCredentials(t *testing.T) {
 			awscluster.APIVersion = "v1alpha3"
 			awscluster.Spec.Provider.CredentialSecret.Name = tc.currentCredential.Name
 			awscluster.Spec.Provider.CredentialSecret.Namespace = tc.currentCredential.Namespace
-			patch, err = mutate.MutateCredential(*awscluster)
+			patch, err = mutate.MutateCredential(*awscluster, key.Organization(awscluster))
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -12,15 +12,30 @@ import (
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/giantswarm/aws-admission-controller/v4/config"
 	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/cidr"
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/validator"
 )
 
+// podPrefixRules are the allowed sizes of the pod CIDR, which AWS CNI adds to
+// the VPC of the cluster. The IPv6 range is used for dual-stack pod networks.
+var podPrefixRules = cidr.PrefixRules{
+	cidr.IPv4: {Min: 16, Max: 28},
+	cidr.IPv6: {Min: 44, Max: 64},
+}
+
 type Validator struct {
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
+
+	dockerCIDR               string
+	gitopsGuard              aws.GitopsGuard
+	restrictedIdentities     aws.RestrictedIdentities
+	ipamNetworkCIDR          string
+	kubernetesClusterIPRange string
 }
 
 func NewValidator(config config.Config) (*Validator, error) {
@@ -34,6 +49,12 @@ func NewValidator(config config.Config) (*Validator, error) {
 	v := &Validator{
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
+
+		dockerCIDR:               config.DockerCIDR,
+		gitopsGuard:              config.Policy.GitopsGuard,
+		restrictedIdentities:     config.Policy.RestrictedIdentities,
+		ipamNetworkCIDR:          config.IPAMNetworkCIDR,
+		kubernetesClusterIPRange: config.KubernetesClusterIPRange,
 	}
 
 	return v, nil
@@ -57,7 +78,12 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Maskf(parsingFailedError, "unable to parse awscluster: %v", err)
 	}
 
-	err = aws.ValidateOrgNamespace(&awsCluster)
+	err = aws.ValidateNamespacePlacement(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "AWSCluster", nil, &awsCluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConstraints("AWSCluster", nil, &awsCluster)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
@@ -101,16 +127,50 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Mask(err)
 	}
 
+	err = v.PodCIDRBlockValid(awsCluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
 	return true, nil
 }
 
 func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool, error) {
 	var awsCluster infrastructurev1alpha3.AWSCluster
+	var awsClusterOld infrastructurev1alpha3.AWSCluster
 	var err error
 
 	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &awsCluster); err != nil {
 		return false, microerror.Maskf(parsingFailedError, "unable to parse awscluster: %v", err)
 	}
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &awsClusterOld); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse old awscluster: %v", err)
+	}
+
+	err = aws.ValidateGitopsPaused(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, v.gitopsGuard, "AWSCluster", &awsClusterOld, &awsCluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelEdit(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, v.restrictedIdentities, request.UserInfo, &awsClusterOld, &awsCluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConsistency(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "AWSCluster", &awsClusterOld, &awsCluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateNamespacePlacement(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "AWSCluster", &awsClusterOld, &awsCluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConstraints("AWSCluster", &awsClusterOld, &awsCluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 
 	err = aws.ValidateOrganizationLabelContainsExistingOrganization(context.Background(), v.k8sClient.CtrlClient(), &awsCluster)
 	if err != nil {
@@ -144,10 +204,55 @@ func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool,
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
+	if awsCluster.Spec.Provider.Pods.CIDRBlock != awsClusterOld.Spec.Provider.Pods.CIDRBlock {
+		err = v.PodCIDRBlockValid(awsCluster)
+		if err != nil {
+			return false, microerror.Mask(err)
+		}
+	}
 
 	return true, nil
 }
 
+// PodCIDRBlockValid checks that the pod CIDR of the AWSCluster does not
+// overlap with network ranges of the installation or other ranges of the
+// cluster.
+func (v *Validator) PodCIDRBlockValid(awsCluster infrastructurev1alpha3.AWSCluster) error {
+	if awsCluster.Spec.Provider.Pods.CIDRBlock == "" {
+		return nil
+	}
+	podNets, err := cidr.ParseList(awsCluster.Spec.Provider.Pods.CIDRBlock)
+	if err != nil {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster pod CIDR block %s is invalid: %v", awsCluster.Spec.Provider.Pods.CIDRBlock, err))
+	}
+	err = podPrefixRules.ValidateList(podNets)
+	if err != nil {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster pod CIDR block is invalid: %v", err))
+	}
+
+	global, err := aws.GlobalReservations(v.dockerCIDR, v.ipamNetworkCIDR, v.kubernetesClusterIPRange)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	reservations, err := aws.FetchReservations(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, global)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, podNet := range podNets {
+		conflict, ok := reservations.Conflict(aws.Reservation{ClusterID: aws.ClusterID(&awsCluster), NetworkPool: awsCluster.Spec.Provider.Nodes.NetworkPool, Network: podNet, Owner: aws.PodCIDROwner(&awsCluster)})
+		if ok {
+			return microerror.Maskf(notAllowedError, fmt.Sprintf("AWSCluster pod CIDR block %s intersects with the %s %s.",
+				podNet.String(),
+				conflict.Owner,
+				conflict.Network.String()),
+			)
+		}
+	}
+
+	return nil
+}
+
 func (v *Validator) AWSClusterAnnotationCNIMinimumIPTarget(awsCluster infrastructurev1alpha3.AWSCluster) error {
 	if cniMinimumIPTarget, ok := awsCluster.GetAnnotations()[annotation.AWSCNIMinimumIPTarget]; ok {
 		if !aws.IsIntegerGreaterThanZero(cniMinimumIPTarget) {
@@ -264,3 +369,7 @@ func (v *Validator) Log(keyVals ...interface{}) {
 func (v *Validator) Resource() string {
 	return "awscluster"
 }
+
+func (v *Validator) GroupVersionKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{aws.AWSClusterGVK}
+}
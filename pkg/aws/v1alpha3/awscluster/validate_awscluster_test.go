@@ -109,3 +109,99 @@ func Test_AWSClusterAlreadyExists(t *testing.T) {
 		})
 	}
 }
+
+func TestPodCIDRBlockValid(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		podCIDRBlock    string
+		networkPoolCIDR string
+		networkPoolUsed bool
+		err             error
+	}{
+		{
+			// No overlap
+			name: "case 0",
+			ctx:  context.Background(),
+
+			podCIDRBlock: "10.2.0.0/16",
+			err:          nil,
+		},
+		{
+			// Overlaps with the Docker CIDR
+			name: "case 1",
+			ctx:  context.Background(),
+
+			podCIDRBlock: "172.17.0.0/16",
+			err:          notAllowedError,
+		},
+		{
+			// Overlaps with the Kubernetes cluster IP range
+			name: "case 2",
+			ctx:  context.Background(),
+
+			podCIDRBlock: "172.31.0.0/24",
+			err:          notAllowedError,
+		},
+		{
+			// Overlaps with the NetworkPool of the cluster
+			name: "case 3",
+			ctx:  context.Background(),
+
+			podCIDRBlock:    "10.2.0.0/16",
+			networkPoolCIDR: "10.2.128.0/20",
+			networkPoolUsed: true,
+			err:             notAllowedError,
+		},
+		{
+			// Invalid CIDR
+			name: "case 4",
+			ctx:  context.Background(),
+
+			podCIDRBlock: "10.2.0.0",
+			err:          notAllowedError,
+		},
+		{
+			// Overlaps with a NetworkPool of other clusters
+			name: "case 5",
+			ctx:  context.Background(),
+
+			podCIDRBlock:    "10.2.0.0/16",
+			networkPoolCIDR: "10.2.128.0/20",
+			err:             nil,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+
+				dockerCIDR:               "172.17.0.1/16",
+				ipamNetworkCIDR:          "10.1.0.0/16",
+				kubernetesClusterIPRange: "172.31.0.0/16",
+			}
+
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.Spec.Provider.Pods.CIDRBlock = tc.podCIDRBlock
+
+			if tc.networkPoolCIDR != "" {
+				networkPool := unittest.DefaultNetworkPool(tc.networkPoolCIDR)
+				err := fakeK8sClient.CtrlClient().Create(tc.ctx, networkPool)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tc.networkPoolUsed {
+					awsCluster.Spec.Provider.Nodes.NetworkPool = networkPool.GetName()
+				}
+			}
+
+			err := validate.PodCIDRBlockValid(*awsCluster)
+			if microerror.Cause(err) != tc.err {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/giantswarm/aws-admission-controller/v4/config"
 	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
@@ -69,6 +70,12 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 		return nil, microerror.Maskf(parsingFailedError, "unable to parse awscontrol plane: %v", err)
 	}
 
+	patch, err = aws.MutateOrganizationLabel(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, awsControlPlaneCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	patch, err = m.MutateReleaseVersion(*awsControlPlaneCR)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -181,6 +188,12 @@ func (m *Mutator) MutateUpdate(request *admissionv1.AdmissionRequest) ([]mutator
 		result = append(result, patch...)
 	}
 
+	patch, err = aws.MutateReleasePropagation(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, awsControlPlaneCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	return result, nil
 }
 
@@ -345,3 +358,7 @@ func (m *Mutator) Log(keyVals ...interface{}) {
 func (m *Mutator) Resource() string {
 	return "awscontrolplane"
 }
+
+func (m *Mutator) GroupVersionKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{aws.AWSControlPlaneGVK}
+}
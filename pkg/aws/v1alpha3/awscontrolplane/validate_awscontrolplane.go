@@ -12,6 +12,7 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/giantswarm/aws-admission-controller/v4/config"
 	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
@@ -24,6 +25,10 @@ type Validator struct {
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
 
+	gitopsGuard            aws.GitopsGuard
+	instanceTypeCatalog    aws.InstanceTypeCatalog
+	instanceTypePolicy     aws.InstanceTypePolicy
+	restrictedIdentities   aws.RestrictedIdentities
 	validAvailabilityZones []string
 	validInstanceTypes     []string
 }
@@ -39,10 +44,19 @@ func NewValidator(config config.Config) (*Validator, error) {
 	var availabilityZones []string = strings.Split(config.AvailabilityZones, ",")
 	var instanceTypes []string = strings.Split(config.MasterInstanceTypes, ",")
 
+	instanceTypeCatalog, err := aws.LoadInstanceTypeCatalog()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
 	validator := &Validator{
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
 
+		gitopsGuard:            config.Policy.GitopsGuard,
+		instanceTypeCatalog:    instanceTypeCatalog,
+		instanceTypePolicy:     config.Policy.InstanceTypes,
+		restrictedIdentities:   config.Policy.RestrictedIdentities,
 		validAvailabilityZones: availabilityZones,
 		validInstanceTypes:     instanceTypes,
 	}
@@ -57,6 +71,27 @@ func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error
 	if request.Operation == admissionv1.Update {
 		return v.ValidateUpdate(request)
 	}
+	if request.Operation == admissionv1.Delete {
+		return v.ValidateDelete(request)
+	}
+	return true, nil
+}
+
+func (v *Validator) ValidateDelete(request *admissionv1.AdmissionRequest) (bool, error) {
+	var awsControlPlane infrastructurev1alpha3.AWSControlPlane
+	var err error
+
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &awsControlPlane); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse awscontrol plane: %v", err)
+	}
+	err = aws.ValidateClusterDeletedFirst(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, &awsControlPlane, "AWSControlPlane")
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = aws.ValidateGitopsPaused(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, v.gitopsGuard, "AWSControlPlane", &awsControlPlane, nil)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 	return true, nil
 }
 
@@ -72,6 +107,31 @@ func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool,
 	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &awsControlPlaneOld); err != nil {
 		return false, microerror.Maskf(parsingFailedError, "unable to parse old awscontrol plane: %v", err)
 	}
+	err = aws.ValidateGitopsPaused(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, v.gitopsGuard, "AWSControlPlane", &awsControlPlaneOld, &awsControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelEdit(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, v.restrictedIdentities, request.UserInfo, &awsControlPlaneOld, &awsControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConsistency(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "AWSControlPlane", &awsControlPlaneOld, &awsControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateNamespacePlacement(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "AWSControlPlane", &awsControlPlaneOld, &awsControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConstraints("AWSControlPlane", &awsControlPlaneOld, &awsControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
 	err = v.AZCount(awsControlPlane)
 	if err != nil {
 		return false, microerror.Mask(err)
@@ -96,6 +156,10 @@ func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool,
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
+	err = v.InstanceTypeSupported(&awsControlPlaneOld, awsControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 	err = v.AnnotationValid(awsControlPlane)
 	if err != nil {
 		return false, microerror.Mask(err)
@@ -125,7 +189,12 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &awsControlPlane); err != nil {
 		return false, microerror.Maskf(parsingFailedError, "unable to parse awscontrol plane: %v", err)
 	}
-	err = aws.ValidateOrgNamespace(&awsControlPlane)
+	err = aws.ValidateNamespacePlacement(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "AWSControlPlane", nil, &awsControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConstraints("AWSControlPlane", nil, &awsControlPlane)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
@@ -155,6 +224,10 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
+	err = v.InstanceTypeSupported(nil, awsControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 	err = v.AnnotationValid(awsControlPlane)
 	if err != nil {
 		return false, microerror.Mask(err)
@@ -301,6 +374,26 @@ func (v *Validator) InstanceTypeValid(awsControlPlane infrastructurev1alpha3.AWS
 	return nil
 }
 
+// InstanceTypeSupported validates the master instance type against the
+// instance type catalog.
+func (v *Validator) InstanceTypeSupported(oldAWSControlPlane *infrastructurev1alpha3.AWSControlPlane, awsControlPlane infrastructurev1alpha3.AWSControlPlane) error {
+	if oldAWSControlPlane != nil &&
+		oldAWSControlPlane.Spec.InstanceType == awsControlPlane.Spec.InstanceType &&
+		strings.Join(oldAWSControlPlane.Spec.AvailabilityZones, ",") == strings.Join(awsControlPlane.Spec.AvailabilityZones, ",") {
+		return nil
+	}
+	err := aws.ValidateInstanceTypeSupported(v.instanceTypeCatalog, v.instanceTypePolicy, "AWSControlPlane", &awsControlPlane, awsControlPlane.Spec.InstanceType, awsControlPlane.Spec.AvailabilityZones)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	err = aws.ValidateMasterInstanceSize(v.instanceTypeCatalog, v.instanceTypePolicy, "AWSControlPlane", &awsControlPlane, awsControlPlane.Spec.InstanceType)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
 func (v *Validator) AnnotationValid(awsControlPlane infrastructurev1alpha3.AWSControlPlane) error {
 	val, ok := awsControlPlane.Annotations[annotation.AWSEBSVolumeIops]
 	if ok {
@@ -391,3 +484,7 @@ func (v *Validator) Log(keyVals ...interface{}) {
 func (v *Validator) Resource() string {
 	return "awscontrolplane"
 }
+
+func (v *Validator) GroupVersionKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{aws.AWSControlPlaneGVK}
+}
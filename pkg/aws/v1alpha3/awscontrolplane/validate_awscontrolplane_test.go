@@ -6,9 +6,12 @@ import (
 	"strconv"
 	"testing"
 
+	infrastructurev1alpha3 "github.com/giantswarm/apiextensions/v6/pkg/apis/infrastructure/v1alpha3"
 	"github.com/giantswarm/k8smetadata/pkg/annotation"
 	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
 	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
 )
 
@@ -410,6 +413,76 @@ func TestInstanceTypeValid(t *testing.T) {
 	}
 }
 
+func TestInstanceTypeSupported(t *testing.T) {
+	testCases := []struct {
+		name            string
+		instanceType    string
+		oldInstanceType string
+
+		allowed bool
+	}{
+		{
+			// Large enough master instance type
+			name:         "case 0",
+			instanceType: "m5.xlarge",
+			allowed:      true,
+		},
+		{
+			// Too small master instance type
+			name:         "case 1",
+			instanceType: "m5.large",
+			allowed:      false,
+		},
+		{
+			// Unchanged too small master instance type on update
+			name:            "case 2",
+			instanceType:    "m5.large",
+			oldInstanceType: "m5.large",
+			allowed:         true,
+		},
+		{
+			// ARM master instance type on a release without ARM support
+			name:         "case 3",
+			instanceType: "m6g.xlarge",
+			allowed:      false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			catalog, err := aws.LoadInstanceTypeCatalog()
+			if err != nil {
+				t.Fatal(err)
+			}
+			validate := &Validator{
+				instanceTypeCatalog: catalog,
+				instanceTypePolicy: aws.InstanceTypePolicy{
+					FirstARMRelease:    "20.0.0",
+					MinMasterCPU:       4,
+					MinMasterMemoryMiB: 8192,
+				},
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			awsControlPlane := unittest.DefaultAWSControlPlane()
+			awsControlPlane.Spec.InstanceType = tc.instanceType
+			var oldAWSControlPlane *infrastructurev1alpha3.AWSControlPlane
+			if tc.oldInstanceType != "" {
+				oldAWSControlPlane = awsControlPlane.DeepCopy()
+				oldAWSControlPlane.Spec.InstanceType = tc.oldInstanceType
+			}
+
+			err = validate.InstanceTypeSupported(oldAWSControlPlane, awsControlPlane)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && !aws.IsNotAllowed(err) {
+				t.Fatalf("expected not allowed error but got %v", err)
+			}
+		})
+	}
+}
+
 func TestAnnotations(t *testing.T) {
 	testCases := []struct {
 		ctx  context.Context
@@ -484,3 +557,68 @@ func TestAnnotations(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateDelete(t *testing.T) {
+	testCases := []struct {
+		name            string
+		clusterExists   bool
+		clusterDeleting bool
+
+		allowed bool
+	}{
+		{
+			// Cluster still exists
+			name:          "case 0",
+			clusterExists: true,
+			allowed:       false,
+		},
+		{
+			// Cluster is being deleted
+			name:            "case 1",
+			clusterExists:   true,
+			clusterDeleting: true,
+			allowed:         true,
+		},
+		{
+			// Cluster is gone
+			name:    "case 2",
+			allowed: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+			}
+
+			if tc.clusterExists {
+				cluster := unittest.DefaultCluster()
+				if tc.clusterDeleting {
+					now := metav1.Now()
+					cluster.SetDeletionTimestamp(&now)
+					cluster.SetFinalizers([]string{"operatorkit.giantswarm.io/cluster-operator"})
+				}
+				err := fakeK8sClient.CtrlClient().Create(context.Background(), cluster)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			request, err := unittest.DeleteAdmissionRequest(unittest.DefaultAWSControlPlane(), metav1.GroupVersionKind(aws.AWSControlPlaneGVK))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			allowed, err := validate.Validate(&request)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if allowed != tc.allowed {
+				t.Fatalf("expected %v to not differ from %v", allowed, tc.allowed)
+			}
+		})
+	}
+}
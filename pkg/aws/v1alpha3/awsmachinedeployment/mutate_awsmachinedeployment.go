@@ -9,6 +9,8 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/giantswarm/aws-admission-controller/v4/config"
 	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
@@ -20,6 +22,8 @@ import (
 var (
 	// If not specified otherwise, node pools should have 100% on-demand instances.
 	defaultOnDemandPercentageAboveBaseCapacity int = 100
+	// If not specified otherwise, node pools have no on-demand base capacity.
+	defaultOnDemandBaseCapacity int = 0
 )
 
 type Config struct {
@@ -76,12 +80,24 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 	if _, _, err := mutator.Deserializer.Decode(request.Object.Raw, nil, awsMachineDeploymentNewCR); err != nil {
 		return nil, microerror.Maskf(parsingFailedError, "unable to parse AWSMachineDeployment: %v", err)
 	}
+
+	patch, err = aws.MutateOrganizationLabel(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, awsMachineDeploymentNewCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
 	patch, err = m.MutateAvailabilityZones(*awsMachineDeploymentNewCR)
 	if err != nil {
 		return nil, microerror.Mask(err)
 	}
 	result = append(result, patch...)
 
+	patch, err = m.MutateOnDemandBaseCapacity(request.Object.Raw)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	patch, err = m.MutateOnDemandPercentage(*awsMachineDeploymentNewCR)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -121,6 +137,12 @@ func (m *Mutator) MutateUpdate(request *admissionv1.AdmissionRequest) ([]mutator
 	if _, _, err := mutator.Deserializer.Decode(request.OldObject.Raw, nil, awsMachineDeploymentOldCR); err != nil {
 		return nil, microerror.Maskf(parsingFailedError, "unable to parse AWSMachineDeployment: %v", err)
 	}
+	patch, err = m.MutateOnDemandBaseCapacity(request.Object.Raw)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	patch, err = m.MutateOnDemandPercentage(*awsMachineDeploymentNewCR)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -130,6 +152,12 @@ func (m *Mutator) MutateUpdate(request *admissionv1.AdmissionRequest) ([]mutator
 	patch = aws.MutateCAPILabel(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, awsMachineDeploymentNewCR)
 	result = append(result, patch...)
 
+	patch, err = aws.MutateReleasePropagation(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, awsMachineDeploymentNewCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	return result, nil
 }
 
@@ -172,6 +200,39 @@ func (m *Mutator) MutateOnDemandPercentage(awsMachineDeployment infrastructurev1
 	return result, nil
 }
 
+// MutateOnDemandBaseCapacity defaults the on-demand base capacity if it is not
+// set. A missing instance distribution is added with the default base capacity
+// and on-demand percentage, so that the patch of MutateOnDemandPercentage
+// applies.
+func (m *Mutator) MutateOnDemandBaseCapacity(raw []byte) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(raw); err != nil {
+		return nil, microerror.Maskf(parsingFailedError, "unable to parse AWSMachineDeployment: %v", err)
+	}
+	instanceDistribution, found, err := unstructured.NestedMap(obj.Object, "spec", "provider", "instanceDistribution")
+	if err != nil {
+		return nil, microerror.Maskf(parsingFailedError, "unable to parse AWSMachineDeployment instance distribution: %v", err)
+	}
+	if !found {
+		m.Log("level", "debug", "message", fmt.Sprintf("AWSMachineDeployment %s InstanceDistribution is not set and will be defaulted", obj.GetName()))
+		patch := mutator.PatchAdd("/spec/provider/instanceDistribution", map[string]int{
+			"onDemandBaseCapacity":                defaultOnDemandBaseCapacity,
+			"onDemandPercentageAboveBaseCapacity": defaultOnDemandPercentageAboveBaseCapacity,
+		})
+		result = append(result, patch)
+		return result, nil
+	}
+	if _, ok := instanceDistribution["onDemandBaseCapacity"]; !ok {
+		m.Log("level", "debug", "message", fmt.Sprintf("AWSMachineDeployment %s OnDemandBaseCapacity is not set and will be set to default %d", obj.GetName(), defaultOnDemandBaseCapacity))
+		patch := mutator.PatchAdd("/spec/provider/instanceDistribution/onDemandBaseCapacity", defaultOnDemandBaseCapacity)
+		result = append(result, patch)
+	}
+
+	return result, nil
+}
+
 func (m *Mutator) MutateOperatorVersion(awsMachineDeployment infrastructurev1alpha3.AWSMachineDeployment) ([]mutator.PatchOperation, error) {
 	var result []mutator.PatchOperation
 	var patch []mutator.PatchOperation
@@ -227,3 +288,7 @@ func (m *Mutator) Log(keyVals ...interface{}) {
 func (m *Mutator) Resource() string {
 	return "awsmachinedeployment"
 }
+
+func (m *Mutator) GroupVersionKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{aws.AWSMachineDeploymentGVK}
+}
@@ -237,3 +237,54 @@ func awsMachineDeployment() *infrastructurev1alpha3.AWSMachineDeployment {
 		},
 	}
 }
+
+func TestAWSMachineDeploymentOnDemandBaseCapacity(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		instanceDistribution string
+
+		expectedPath string
+	}{
+		{
+			// Don't default a set base capacity
+			name:                 "case 0",
+			instanceDistribution: `,"instanceDistribution":{"onDemandBaseCapacity":2}`,
+			expectedPath:         "",
+		},
+		{
+			// Default a missing base capacity
+			name:                 "case 1",
+			instanceDistribution: `,"instanceDistribution":{"onDemandPercentageAboveBaseCapacity":50}`,
+			expectedPath:         "/spec/provider/instanceDistribution/onDemandBaseCapacity",
+		},
+		{
+			// Default a missing instance distribution
+			name:                 "case 2",
+			instanceDistribution: "",
+			expectedPath:         "/spec/provider/instanceDistribution",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			mutate := &Mutator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			raw := []byte(`{"apiVersion":"infrastructure.giantswarm.io/v1alpha3","kind":"AWSMachineDeployment","metadata":{"name":"` + machineDeploymentID + `"},"spec":{"provider":{"worker":{"instanceType":"m5.xlarge"}` + tc.instanceDistribution + `}}}`)
+			patch, err := mutate.MutateOnDemandBaseCapacity(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var path string
+			for _, p := range patch {
+				path = p.Path
+			}
+			if tc.expectedPath != path {
+				t.Fatalf("expected %#q to be equal to %#q", tc.expectedPath, path)
+			}
+		})
+	}
+}
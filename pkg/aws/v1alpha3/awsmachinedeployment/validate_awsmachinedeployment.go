@@ -12,8 +12,10 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/giantswarm/aws-admission-controller/v4/config"
 	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
@@ -26,6 +28,11 @@ type Validator struct {
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
 
+	gitopsGuard            aws.GitopsGuard
+	instanceTypeCatalog    aws.InstanceTypeCatalog
+	instanceTypePolicy     aws.InstanceTypePolicy
+	restrictedIdentities   aws.RestrictedIdentities
+	scalingPolicy          aws.ScalingPolicy
 	validAvailabilityZones []string
 	validInstanceTypes     []string
 }
@@ -41,10 +48,20 @@ func NewValidator(config config.Config) (*Validator, error) {
 	var availabilityZones []string = strings.Split(config.AvailabilityZones, ",")
 	var instanceTypes []string = strings.Split(config.WorkerInstanceTypes, ",")
 
+	instanceTypeCatalog, err := aws.LoadInstanceTypeCatalog()
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
 	validator := &Validator{
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
 
+		gitopsGuard:            config.Policy.GitopsGuard,
+		instanceTypeCatalog:    instanceTypeCatalog,
+		instanceTypePolicy:     config.Policy.InstanceTypes,
+		restrictedIdentities:   config.Policy.RestrictedIdentities,
+		scalingPolicy:          config.Policy.Scaling,
 		validAvailabilityZones: availabilityZones,
 		validInstanceTypes:     instanceTypes,
 	}
@@ -64,17 +81,51 @@ func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error
 
 func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool, error) {
 	var awsMachineDeployment infrastructurev1alpha3.AWSMachineDeployment
+	var awsMachineDeploymentOld infrastructurev1alpha3.AWSMachineDeployment
 	var err error
 
 	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &awsMachineDeployment); err != nil {
 		return false, microerror.Maskf(parsingFailedError, "unable to parse awsmachinedeployment: %v", err)
 	}
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &awsMachineDeploymentOld); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse old awsmachinedeployment: %v", err)
+	}
+
+	err = aws.ValidateGitopsPaused(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, v.gitopsGuard, "AWSMachineDeployment", &awsMachineDeploymentOld, &awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelEdit(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, v.restrictedIdentities, request.UserInfo, &awsMachineDeploymentOld, &awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConsistency(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "AWSMachineDeployment", &awsMachineDeploymentOld, &awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateNamespacePlacement(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "AWSMachineDeployment", &awsMachineDeploymentOld, &awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConstraints("AWSMachineDeployment", &awsMachineDeploymentOld, &awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 
 	err = v.InstanceTypeValid(awsMachineDeployment)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
 
+	err = v.InstanceTypeSupported(&awsMachineDeploymentOld, awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
 	err = v.AZValid(awsMachineDeployment)
 	if err != nil {
 		return false, microerror.Mask(err)
@@ -95,7 +146,17 @@ func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Mask(err)
 	}
 
-	err = v.MachineDeploymentScaling(awsMachineDeployment)
+	err = v.MachineDeploymentScaling(&awsMachineDeploymentOld, awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.InstanceDistributionValid(&awsMachineDeploymentOld, awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.MachineDeploymentScalingPolicy(&awsMachineDeploymentOld, awsMachineDeployment)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
@@ -116,6 +177,16 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Mask(err)
 	}
 
+	err = aws.ValidateNamespacePlacement(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "AWSMachineDeployment", nil, &awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConstraints("AWSMachineDeployment", nil, &awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
 	err = aws.ValidateOperatorVersion(&awsMachineDeployment)
 	if err != nil {
 		return false, microerror.Mask(err)
@@ -126,6 +197,11 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Mask(err)
 	}
 
+	err = v.InstanceTypeSupported(nil, awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
 	err = v.AZValid(awsMachineDeployment)
 	if err != nil {
 		return false, microerror.Mask(err)
@@ -151,7 +227,17 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Mask(err)
 	}
 
-	err = v.MachineDeploymentScaling(awsMachineDeployment)
+	err = v.MachineDeploymentScaling(nil, awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.InstanceDistributionValid(nil, awsMachineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.MachineDeploymentScalingPolicy(nil, awsMachineDeployment)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
@@ -183,6 +269,24 @@ func (v *Validator) InstanceTypeValid(awsMachineDeployment infrastructurev1alpha
 	return nil
 }
 
+// InstanceTypeSupported validates the worker instance type against the
+// instance type catalog.
+func (v *Validator) InstanceTypeSupported(oldMD *infrastructurev1alpha3.AWSMachineDeployment, md infrastructurev1alpha3.AWSMachineDeployment) error {
+	instanceType := md.Spec.Provider.Worker.InstanceType
+	availabilityZones := md.Spec.Provider.AvailabilityZones
+	if oldMD != nil &&
+		oldMD.Spec.Provider.Worker.InstanceType == instanceType &&
+		strings.Join(oldMD.Spec.Provider.AvailabilityZones, ",") == strings.Join(availabilityZones, ",") {
+		return nil
+	}
+	err := aws.ValidateInstanceTypeSupported(v.instanceTypeCatalog, v.instanceTypePolicy, "AWSMachineDeployment", &md, instanceType, availabilityZones)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
 func (v *Validator) MachineDeploymentLabelMatch(awsMachineDeployment infrastructurev1alpha3.AWSMachineDeployment) error {
 	var machineDeployment capi.MachineDeployment
 	var err error
@@ -286,13 +390,121 @@ func (v *Validator) ValidateCluster(awsMachineDeployment infrastructurev1alpha3.
 	return nil
 }
 
-func (v *Validator) MachineDeploymentScaling(md infrastructurev1alpha3.AWSMachineDeployment) error {
+// MachineDeploymentScaling validates the scaling of the node pool. On update a
+// zero minimum with an on-demand base capacity is only denied when either of
+// them changes. oldMD is nil on create.
+func (v *Validator) MachineDeploymentScaling(oldMD *infrastructurev1alpha3.AWSMachineDeployment, md infrastructurev1alpha3.AWSMachineDeployment) error {
 	min := md.Spec.NodePool.Scaling.Min
 	max := md.Spec.NodePool.Scaling.Max
+	baseCapacity := md.Spec.Provider.InstanceDistribution.OnDemandBaseCapacity
 
 	if min > max {
 		return microerror.Maskf(notAllowedError, "AWSMachineDeployment.Spec.Scaling.Min must not be greater that AWSMachineDeployment.Spec.Scaling.Max.")
 	}
+	if oldMD != nil && oldMD.Spec.NodePool.Scaling.Min == min && oldMD.Spec.Provider.InstanceDistribution.OnDemandBaseCapacity == baseCapacity {
+		return nil
+	}
+	if min == 0 && baseCapacity > 0 {
+		return microerror.Maskf(notAllowedError, "AWSMachineDeployment.Spec.Scaling.Min must not be 0 when AWSMachineDeployment.Spec.Provider.InstanceDistribution.OnDemandBaseCapacity is set.")
+	}
+
+	return nil
+}
+
+// InstanceDistributionValid validates the on-demand percentage and base
+// capacity, and that all instance types alike to the worker instance type are
+// valid when alike instance types are used. On update the base capacity is
+// only validated when it or the scaling maximum changes, and the alike
+// instance types only when the worker changes. oldMD is nil on create.
+func (v *Validator) InstanceDistributionValid(oldMD *infrastructurev1alpha3.AWSMachineDeployment, md infrastructurev1alpha3.AWSMachineDeployment) error {
+	instanceDistribution := md.Spec.Provider.InstanceDistribution
+
+	percentage := instanceDistribution.OnDemandPercentageAboveBaseCapacity
+	if percentage != nil && (*percentage < 0 || *percentage > 100) {
+		return microerror.Maskf(notAllowedError, "AWSMachineDeployment.Spec.Provider.InstanceDistribution.OnDemandPercentageAboveBaseCapacity %d must be between 0 and 100.",
+			*percentage)
+	}
+	baseCapacityChanged := oldMD == nil ||
+		oldMD.Spec.Provider.InstanceDistribution.OnDemandBaseCapacity != instanceDistribution.OnDemandBaseCapacity ||
+		oldMD.Spec.NodePool.Scaling.Max != md.Spec.NodePool.Scaling.Max
+	if baseCapacityChanged && (instanceDistribution.OnDemandBaseCapacity < 0 || instanceDistribution.OnDemandBaseCapacity > md.Spec.NodePool.Scaling.Max) {
+		return microerror.Maskf(notAllowedError, "AWSMachineDeployment.Spec.Provider.InstanceDistribution.OnDemandBaseCapacity %d must be between 0 and AWSMachineDeployment.Spec.Scaling.Max %d.",
+			instanceDistribution.OnDemandBaseCapacity,
+			md.Spec.NodePool.Scaling.Max)
+	}
+
+	if !md.Spec.Provider.Worker.UseAlikeInstanceTypes {
+		return nil
+	}
+	if oldMD != nil && oldMD.Spec.Provider.Worker == md.Spec.Provider.Worker {
+		return nil
+	}
+	var invalid []string
+	for _, instanceType := range v.instanceTypeCatalog.AlikeInstanceTypes(md.Spec.Provider.Worker.InstanceType) {
+		if !aws.Contains(v.validInstanceTypes, instanceType) {
+			invalid = append(invalid, instanceType)
+		}
+	}
+	if len(invalid) > 0 {
+		return microerror.Maskf(notAllowedError, "AWSMachineDeployment %s can not use alike instance types because %s are not valid worker instance types. Valid instance types are: %v",
+			key.MachineDeployment(&md),
+			strings.Join(invalid, ", "),
+			v.validInstanceTypes)
+	}
+
+	return nil
+}
+
+// MachineDeploymentScalingPolicy validates the node pool against the scaling
+// limits of its cluster. On update the limits are only validated when the
+// scaling maximum grows, so that node pools exceeding lowered limits can still
+// be changed otherwise. oldMD is nil on create.
+func (v *Validator) MachineDeploymentScalingPolicy(oldMD *infrastructurev1alpha3.AWSMachineDeployment, md infrastructurev1alpha3.AWSMachineDeployment) error {
+	max := md.Spec.NodePool.Scaling.Max
+	if oldMD != nil && max <= oldMD.Spec.NodePool.Scaling.Max {
+		return nil
+	}
+	limits := v.scalingPolicy.Limits(key.Cluster(&md), key.Organization(&md))
+
+	if limits.MaxNodesPerNodePool != 0 && max > limits.MaxNodesPerNodePool {
+		return microerror.Maskf(notAllowedError, "AWSMachineDeployment.Spec.Scaling.Max %d exceeds the limit of %d nodes per node pool of cluster %s.",
+			max,
+			limits.MaxNodesPerNodePool,
+			key.Cluster(&md))
+	}
+	if limits.MaxWorkers == 0 && limits.MaxNodePools == 0 {
+		return nil
+	}
+
+	var awsMachineDeployments infrastructurev1alpha3.AWSMachineDeploymentList
+	err := v.k8sClient.CtrlClient().List(context.Background(), &awsMachineDeployments,
+		client.InNamespace(md.GetNamespace()),
+		client.MatchingLabels{label.Cluster: key.Cluster(&md)},
+	)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	workers := max
+	nodePools := 1
+	for _, other := range awsMachineDeployments.Items {
+		if other.GetName() == md.GetName() {
+			continue
+		}
+		workers += other.Spec.NodePool.Scaling.Max
+		nodePools++
+	}
+
+	if oldMD == nil && limits.MaxNodePools != 0 && nodePools > limits.MaxNodePools {
+		return microerror.Maskf(notAllowedError, "Cluster %s can not have more than %d node pools.",
+			key.Cluster(&md),
+			limits.MaxNodePools)
+	}
+	if limits.MaxWorkers != 0 && workers > limits.MaxWorkers {
+		return microerror.Maskf(notAllowedError, "The node pools of cluster %s would scale to %d workers, which exceeds the limit of %d workers.",
+			key.Cluster(&md),
+			workers,
+			limits.MaxWorkers)
+	}
 
 	return nil
 }
@@ -304,3 +516,7 @@ func (v *Validator) Log(keyVals ...interface{}) {
 func (v *Validator) Resource() string {
 	return "awsmachinedeployment"
 }
+
+func (v *Validator) GroupVersionKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{aws.AWSMachineDeploymentGVK}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/giantswarm/micrologger/microloggertest"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
 	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
 )
@@ -324,8 +325,11 @@ func TestValidateClusterNamespace(t *testing.T) {
 
 func TestValidateMachineDeploymentScaling(t *testing.T) {
 	testCases := []struct {
-		scaling infrastructurev1alpha3.AWSMachineDeploymentSpecNodePoolScaling
-		matcher func(error) bool
+		scaling         infrastructurev1alpha3.AWSMachineDeploymentSpecNodePoolScaling
+		baseCapacity    int
+		update          bool
+		oldBaseCapacity int
+		matcher         func(error) bool
 	}{
 		{
 			// case 0
@@ -391,6 +395,46 @@ func TestValidateMachineDeploymentScaling(t *testing.T) {
 			},
 			matcher: nil,
 		},
+		{
+			// case 8
+			scaling: infrastructurev1alpha3.AWSMachineDeploymentSpecNodePoolScaling{
+				Min: 0,
+				Max: 10,
+			},
+			baseCapacity: 2,
+			matcher:      IsNotAllowed,
+		},
+		{
+			// case 9
+			scaling: infrastructurev1alpha3.AWSMachineDeploymentSpecNodePoolScaling{
+				Min: 1,
+				Max: 10,
+			},
+			baseCapacity: 2,
+			matcher:      nil,
+		},
+		{
+			// case 10
+			scaling: infrastructurev1alpha3.AWSMachineDeploymentSpecNodePoolScaling{
+				Min: 0,
+				Max: 10,
+			},
+			baseCapacity:    2,
+			update:          true,
+			oldBaseCapacity: 2,
+			matcher:         nil,
+		},
+		{
+			// case 11
+			scaling: infrastructurev1alpha3.AWSMachineDeploymentSpecNodePoolScaling{
+				Min: 0,
+				Max: 10,
+			},
+			baseCapacity:    3,
+			update:          true,
+			oldBaseCapacity: 2,
+			matcher:         IsNotAllowed,
+		},
 	}
 
 	for i, tc := range testCases {
@@ -403,8 +447,14 @@ func TestValidateMachineDeploymentScaling(t *testing.T) {
 			md := unittest.DefaultAWSMachineDeployment()
 
 			md.Spec.NodePool.Scaling = tc.scaling
+			md.Spec.Provider.InstanceDistribution.OnDemandBaseCapacity = tc.baseCapacity
+			var oldMD *infrastructurev1alpha3.AWSMachineDeployment
+			if tc.update {
+				oldMD = md.DeepCopy()
+				oldMD.Spec.Provider.InstanceDistribution.OnDemandBaseCapacity = tc.oldBaseCapacity
+			}
 
-			err := v.MachineDeploymentScaling(*md)
+			err := v.MachineDeploymentScaling(oldMD, *md)
 			switch {
 			case err == nil && tc.matcher == nil:
 				// correct; carry on
@@ -418,3 +468,334 @@ func TestValidateMachineDeploymentScaling(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateMachineDeploymentScalingPolicy(t *testing.T) {
+	policy := aws.ScalingPolicy{
+		Default: aws.ScalingLimits{
+			MaxNodesPerNodePool: 20,
+			MaxWorkers:          30,
+			MaxNodePools:        2,
+		},
+		Clusters: map[string]aws.ScalingLimits{
+			"other":     {MaxNodePools: 1},
+			"unlimited": {MaxNodesPerNodePool: aws.ScalingUnlimited, MaxWorkers: aws.ScalingUnlimited},
+		},
+	}
+
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		clusterID string
+		oldMax    int
+		max       int
+		otherMax  int
+
+		allowed bool
+	}{
+		{
+			// Node pool within all limits
+			ctx:  context.Background(),
+			name: "case 0",
+
+			clusterID: unittest.DefaultClusterID,
+			max:       10,
+			otherMax:  10,
+			allowed:   true,
+		},
+		{
+			// Node pool exceeds the limit per node pool
+			ctx:  context.Background(),
+			name: "case 1",
+
+			clusterID: unittest.DefaultClusterID,
+			max:       25,
+			otherMax:  0,
+			allowed:   false,
+		},
+		{
+			// Node pools exceed the worker limit of the cluster
+			ctx:  context.Background(),
+			name: "case 2",
+
+			clusterID: unittest.DefaultClusterID,
+			max:       15,
+			otherMax:  20,
+			allowed:   false,
+		},
+		{
+			// Second node pool exceeds the node pool limit of the cluster
+			ctx:  context.Background(),
+			name: "case 3",
+
+			clusterID: "other",
+			max:       5,
+			otherMax:  5,
+			allowed:   false,
+		},
+		{
+			// Scaling down on update although the worker limit is exceeded
+			ctx:  context.Background(),
+			name: "case 4",
+
+			clusterID: unittest.DefaultClusterID,
+			oldMax:    20,
+			max:       15,
+			otherMax:  20,
+			allowed:   true,
+		},
+		{
+			// Cluster lifts the default limits
+			ctx:  context.Background(),
+			name: "case 5",
+
+			clusterID: "unlimited",
+			max:       25,
+			otherMax:  20,
+			allowed:   true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+
+				scalingPolicy: policy,
+			}
+
+			// create another node pool of the cluster
+			other := unittest.DefaultAWSMachineDeployment()
+			other.SetName("other")
+			other.Labels[label.Cluster] = tc.clusterID
+			other.Spec.NodePool.Scaling.Max = tc.otherMax
+			err = fakeK8sClient.CtrlClient().Create(tc.ctx, other)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			object := unittest.DefaultAWSMachineDeployment()
+			object.Labels[label.Cluster] = tc.clusterID
+			object.Spec.NodePool.Scaling.Max = tc.max
+			var oldObject *infrastructurev1alpha3.AWSMachineDeployment
+			if tc.oldMax != 0 {
+				oldObject = object.DeepCopy()
+				oldObject.Spec.NodePool.Scaling.Max = tc.oldMax
+			}
+			err = validate.MachineDeploymentScalingPolicy(oldObject, *object)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && !IsNotAllowed(err) {
+				t.Fatalf("expected not allowed error but got %v", err)
+			}
+		})
+	}
+}
+
+func TestInstanceDistributionValid(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		percentage            int
+		baseCapacity          int
+		instanceType          string
+		useAlikeInstanceTypes bool
+		oldInstanceType       string
+		oldBaseCapacity       int
+
+		allowed bool
+	}{
+		{
+			// Valid instance distribution with alike instance types
+			name:                  "case 0",
+			percentage:            50,
+			baseCapacity:          2,
+			instanceType:          "m5.xlarge",
+			useAlikeInstanceTypes: true,
+			allowed:               true,
+		},
+		{
+			// Percentage above 100
+			name:         "case 1",
+			percentage:   120,
+			instanceType: "m5.xlarge",
+			allowed:      false,
+		},
+		{
+			// Base capacity above the scaling maximum
+			name:         "case 2",
+			percentage:   100,
+			baseCapacity: 6,
+			instanceType: "m5.xlarge",
+			allowed:      false,
+		},
+		{
+			// Alike instance types which are not valid
+			name:                  "case 3",
+			percentage:            100,
+			instanceType:          "r5.xlarge",
+			useAlikeInstanceTypes: true,
+			allowed:               false,
+		},
+		{
+			// Invalid alike instance types of an unchanged worker on update
+			name:                  "case 4",
+			percentage:            100,
+			instanceType:          "r5.xlarge",
+			useAlikeInstanceTypes: true,
+			oldInstanceType:       "r5.xlarge",
+			allowed:               true,
+		},
+		{
+			// Unchanged base capacity above the scaling maximum on update
+			name:            "case 5",
+			percentage:      100,
+			baseCapacity:    6,
+			instanceType:    "m5.xlarge",
+			oldInstanceType: "m5.xlarge",
+			oldBaseCapacity: 6,
+			allowed:         true,
+		},
+		{
+			// Base capacity raised above the scaling maximum on update
+			name:            "case 6",
+			percentage:      100,
+			baseCapacity:    7,
+			instanceType:    "m5.xlarge",
+			oldInstanceType: "m5.xlarge",
+			oldBaseCapacity: 6,
+			allowed:         false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			catalog, err := aws.LoadInstanceTypeCatalog()
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+
+				instanceTypeCatalog: catalog,
+				validInstanceTypes:  append(catalog.AlikeInstanceTypes("m5.xlarge"), "r5.xlarge"),
+			}
+
+			md := unittest.DefaultAWSMachineDeployment()
+			md.Spec.Provider.InstanceDistribution.OnDemandPercentageAboveBaseCapacity = &tc.percentage
+			md.Spec.Provider.InstanceDistribution.OnDemandBaseCapacity = tc.baseCapacity
+			md.Spec.Provider.Worker.InstanceType = tc.instanceType
+			md.Spec.Provider.Worker.UseAlikeInstanceTypes = tc.useAlikeInstanceTypes
+			var oldMD *infrastructurev1alpha3.AWSMachineDeployment
+			if tc.oldInstanceType != "" {
+				oldMD = md.DeepCopy()
+				oldMD.Spec.Provider.Worker.InstanceType = tc.oldInstanceType
+				oldMD.Spec.Provider.InstanceDistribution.OnDemandBaseCapacity = tc.oldBaseCapacity
+			}
+
+			err = v.InstanceDistributionValid(oldMD, *md)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && !IsNotAllowed(err) {
+				t.Fatalf("expected not allowed error but got %v", err)
+			}
+		})
+	}
+}
+
+func TestInstanceTypeSupported(t *testing.T) {
+	testCases := []struct {
+		name              string
+		instanceType      string
+		availabilityZones []string
+		release           string
+		oldInstanceType   string
+
+		allowed bool
+	}{
+		{
+			// Instance type available in the availability zones
+			name:              "case 0",
+			instanceType:      "m6i.xlarge",
+			availabilityZones: []string{"us-east-1a", "us-east-1b"},
+			release:           "100.0.0",
+			allowed:           true,
+		},
+		{
+			// Instance type unavailable in an availability zone
+			name:              "case 1",
+			instanceType:      "m6i.xlarge",
+			availabilityZones: []string{"us-east-1a", "us-east-1e"},
+			release:           "100.0.0",
+			allowed:           false,
+		},
+		{
+			// Unchanged unavailable instance type on update
+			name:              "case 2",
+			instanceType:      "m6i.xlarge",
+			availabilityZones: []string{"us-east-1a", "us-east-1e"},
+			release:           "100.0.0",
+			oldInstanceType:   "m6i.xlarge",
+			allowed:           true,
+		},
+		{
+			// ARM instance type on a release without ARM support
+			name:              "case 3",
+			instanceType:      "m6g.xlarge",
+			availabilityZones: []string{"us-east-1a"},
+			release:           "17.0.0",
+			allowed:           false,
+		},
+		{
+			// Changed to an ARM instance type on update
+			name:              "case 4",
+			instanceType:      "m6g.xlarge",
+			availabilityZones: []string{"us-east-1a"},
+			release:           "17.0.0",
+			oldInstanceType:   "m5.xlarge",
+			allowed:           false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			catalog, err := aws.LoadInstanceTypeCatalog()
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+
+				instanceTypeCatalog: catalog,
+				instanceTypePolicy: aws.InstanceTypePolicy{
+					FirstARMRelease:              "20.0.0",
+					UnavailableAvailabilityZones: map[string][]string{"m6i.xlarge": {"us-east-1e"}},
+				},
+			}
+
+			md := unittest.DefaultAWSMachineDeployment()
+			md.Labels[label.Release] = tc.release
+			md.Spec.Provider.Worker.InstanceType = tc.instanceType
+			md.Spec.Provider.AvailabilityZones = tc.availabilityZones
+			var oldMD *infrastructurev1alpha3.AWSMachineDeployment
+			if tc.oldInstanceType != "" {
+				oldMD = md.DeepCopy()
+				oldMD.Spec.Provider.Worker.InstanceType = tc.oldInstanceType
+			}
+
+			err = v.InstanceTypeSupported(oldMD, *md)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && !aws.IsNotAllowed(err) {
+				t.Fatalf("expected not allowed error but got %v", err)
+			}
+		})
+	}
+}
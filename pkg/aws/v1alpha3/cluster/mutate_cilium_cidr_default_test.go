@@ -2,6 +2,8 @@ package cluster
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"strconv"
 	"testing"
 
@@ -23,9 +25,14 @@ func TestCiliumCIDRDefaulting(t *testing.T) {
 		newVersion     string
 		oldVersion     string
 		customPodCidr  bool
+		podCidr        string
+		noAWSCluster   bool
 		networkPool    bool
 		eniModeEnabled bool
+		supernet       string
+		usedCidrs      []string
 		expectedCidr   string
+		expectedError  bool
 	}{
 		{
 			name: "case 0: no upgrade",
@@ -86,6 +93,75 @@ func TestCiliumCIDRDefaulting(t *testing.T) {
 			eniModeEnabled: true,
 			expectedCidr:   "",
 		},
+		{
+			name: "case 7: upgrade from v18 to v19 with networkpool set and supernet configured",
+			ctx:  context.Background(),
+
+			newVersion:   "18.0.0",
+			oldVersion:   "19.0.0",
+			networkPool:  true,
+			supernet:     "100.64.0.0/14",
+			expectedCidr: "100.64.0.0/16",
+		},
+		{
+			name: "case 8: upgrade from v18 to v19 skipping networks in use",
+			ctx:  context.Background(),
+
+			newVersion:   "18.0.0",
+			oldVersion:   "19.0.0",
+			supernet:     "100.64.0.0/14",
+			usedCidrs:    []string{"100.64.0.0/16", "100.65.0.0/24"},
+			expectedCidr: "100.66.0.0/16",
+		},
+		{
+			name: "case 9: upgrade from v18 to v19 skipping the pod cidr",
+			ctx:  context.Background(),
+
+			newVersion:   "18.0.0",
+			oldVersion:   "19.0.0",
+			podCidr:      "10.196.0.0/16",
+			supernet:     "10.196.0.0/14",
+			expectedCidr: "10.197.0.0/16",
+		},
+		{
+			name: "case 10: upgrade from v18 to v19 with exhausted supernet",
+			ctx:  context.Background(),
+
+			newVersion:    "18.0.0",
+			oldVersion:    "19.0.0",
+			supernet:      "100.64.0.0/15",
+			usedCidrs:     []string{"100.64.0.0/16", "100.65.0.0/16"},
+			expectedError: true,
+		},
+		{
+			name: "case 11: upgrade from v18 to v19 with ENI mode set and supernet configured",
+			ctx:  context.Background(),
+
+			newVersion:     "18.0.0",
+			oldVersion:     "19.0.0",
+			eniModeEnabled: true,
+			supernet:       "100.64.0.0/14",
+			expectedCidr:   "",
+		},
+		{
+			name: "case 12: upgrade from v18 to v19 without AWSCluster and supernet configured",
+			ctx:  context.Background(),
+
+			newVersion:   "18.0.0",
+			oldVersion:   "19.0.0",
+			noAWSCluster: true,
+			supernet:     "100.64.0.0/14",
+			expectedCidr: "",
+		},
+		{
+			name: "case 13: upgrade from v18 to v19 without AWSCluster",
+			ctx:  context.Background(),
+
+			newVersion:   "18.0.0",
+			oldVersion:   "19.0.0",
+			noAWSCluster: true,
+			expectedCidr: "",
+		},
 	}
 	for i, tc := range testCases {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
@@ -97,6 +173,13 @@ func TestCiliumCIDRDefaulting(t *testing.T) {
 				logger:               microloggertest.New(),
 				defaultAWSCNIPodCidr: "10.10.0.0/16",
 				defaultCiliumPodCidr: "192.168.0.0/16",
+				ciliumPodCidrSize:    16,
+			}
+			if tc.supernet != "" {
+				_, mutate.ciliumPodCidrSupernet, err = net.ParseCIDR(tc.supernet)
+				if err != nil {
+					t.Fatal(err)
+				}
 			}
 			// create releases
 			releases := []releasev1alpha1.Release{
@@ -118,9 +201,30 @@ func TestCiliumCIDRDefaulting(t *testing.T) {
 			if tc.customPodCidr {
 				awsCluster.Spec.Provider.Pods.CIDRBlock = "10.199.0.0/16"
 			}
-			err = fakeK8sClient.CtrlClient().Create(tc.ctx, awsCluster)
-			if err != nil {
-				t.Fatal(err)
+			if tc.podCidr != "" {
+				awsCluster.Spec.Provider.Pods.CIDRBlock = tc.podCidr
+			}
+			if !tc.noAWSCluster {
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, awsCluster)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// create networks in use by other clusters and networkpools
+			for j, usedCidr := range tc.usedCidrs {
+				if j%2 == 0 {
+					other := unittest.DefaultCluster()
+					other.SetName(fmt.Sprintf("other%d", j))
+					other.SetLabels(map[string]string{label.Cluster: other.GetName()})
+					other.SetAnnotations(map[string]string{annotation.CiliumPodCidr: usedCidr})
+					err = fakeK8sClient.CtrlClient().Create(tc.ctx, other)
+				} else {
+					err = fakeK8sClient.CtrlClient().Create(tc.ctx, unittest.DefaultNetworkPool(usedCidr))
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
 			}
 
 			// create old and new objects
@@ -144,6 +248,12 @@ func TestCiliumCIDRDefaulting(t *testing.T) {
 			newV := semver.MustParse(tc.newVersion)
 
 			patch, err = mutate.DefaultCiliumCidrOnV18Upgrade(*cluster, &oldV, &newV)
+			if tc.expectedError {
+				if !IsNotAllowed(err) {
+					t.Fatalf("%s: expected not allowed error, got %v", tc.name, err)
+				}
+				return
+			}
 			if err != nil {
 				t.Fatal(err)
 			}
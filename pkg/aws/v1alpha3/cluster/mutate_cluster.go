@@ -3,6 +3,9 @@ package cluster
 
 import (
 	"fmt"
+	"net"
+	"strings"
+	"time"
 
 	"github.com/blang/semver/v4"
 	"github.com/giantswarm/k8sclient/v7/pkg/k8sclient"
@@ -12,10 +15,12 @@ import (
 	admissionv1 "k8s.io/api/admission/v1"
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 
 	"github.com/giantswarm/aws-admission-controller/v4/config"
 	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/cidr"
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/key"
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/mutator"
@@ -33,6 +38,12 @@ type Mutator struct {
 
 	defaultAWSCNIPodCidr string
 	defaultCiliumPodCidr string
+
+	ciliumPodCidrSupernet    *net.IPNet
+	ciliumPodCidrSize        int
+	dockerCIDR               string
+	ipamNetworkCIDR          string
+	kubernetesClusterIPRange string
 }
 
 func NewMutator(config config.Config) (*Mutator, error) {
@@ -46,12 +57,31 @@ func NewMutator(config config.Config) (*Mutator, error) {
 		return nil, microerror.Maskf(invalidConfigError, "%T.CiliumDefaultPodCidr must not be empty", config)
 	}
 
+	var ciliumPodCidrSupernet *net.IPNet
+	if config.CiliumPodCidrSupernet != "" {
+		var err error
+		ciliumPodCidrSupernet, err = cidr.Parse(config.CiliumPodCidrSupernet)
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "%T.CiliumPodCidrSupernet is invalid: %v", config, err)
+		}
+		ones, bits := ciliumPodCidrSupernet.Mask.Size()
+		if config.CiliumPodCidrSize < ones || config.CiliumPodCidrSize > bits {
+			return nil, microerror.Maskf(invalidConfigError, "%T.CiliumPodCidrSize must be between %d and %d", config, ones, bits)
+		}
+	}
+
 	mutator := &Mutator{
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
 
 		defaultAWSCNIPodCidr: fmt.Sprintf("%s/%s", config.PodSubnet, config.PodCIDR),
 		defaultCiliumPodCidr: config.CiliumDefaultPodCidr,
+
+		ciliumPodCidrSupernet:    ciliumPodCidrSupernet,
+		ciliumPodCidrSize:        config.CiliumPodCidrSize,
+		dockerCIDR:               config.DockerCIDR,
+		ipamNetworkCIDR:          config.IPAMNetworkCIDR,
+		kubernetesClusterIPRange: config.KubernetesClusterIPRange,
 	}
 
 	return mutator, nil
@@ -93,6 +123,12 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 		return result, nil
 	}
 
+	patch, err = aws.MutateOrganizationLabel(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, cluster)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	patch, err = m.MutateReleaseVersion(*cluster)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -118,6 +154,9 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 	}
 	result = append(result, patch...)
 
+	patch = m.MutateUpgradeScheduleTime(*cluster)
+	result = append(result, patch...)
+
 	return result, nil
 }
 
@@ -173,6 +212,10 @@ func (m *Mutator) MutateUpdate(request *admissionv1.AdmissionRequest) ([]mutator
 	}
 	result = append(result, patch...)
 
+	// Has to run after DefaultCiliumCidrOnV18Upgrade, which replaces all annotations.
+	patch = m.MutateUpgradeScheduleTime(*cluster)
+	result = append(result, patch...)
+
 	return result, nil
 }
 
@@ -241,12 +284,17 @@ func (m *Mutator) MutateReleaseUpdate(cluster capi.Cluster, oldCluster capi.Clus
 		return nil, microerror.Mask(err)
 	}
 
-	// mutate the operator label
-	patch, err = aws.MutateLabelFromRelease(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &cluster, *release, label.ClusterOperatorVersion, "cluster-operator")
-	if err != nil {
-		return nil, microerror.Mask(err)
+	// mutate the operator labels, which are propagated to the other CRs of the cluster
+	for _, operator := range aws.OperatorVersionLabels() {
+		if operator[0] != label.ClusterOperatorVersion && cluster.GetLabels()[operator[0]] == "" {
+			continue
+		}
+		patch, err = aws.MutateLabelFromRelease(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &cluster, *release, operator[0], operator[1])
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		result = append(result, patch...)
 	}
-	result = append(result, patch...)
 
 	return result, nil
 }
@@ -259,6 +307,10 @@ func (m *Mutator) Resource() string {
 	return "cluster"
 }
 
+func (m *Mutator) GroupVersionKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{aws.ClusterGVK}
+}
+
 func (m *Mutator) MutateInfraRef(cluster capi.Cluster, releaseVersion *semver.Version) ([]mutator.PatchOperation, error) {
 	var result []mutator.PatchOperation
 	if cluster.Spec.InfrastructureRef.Name != "" && cluster.Spec.InfrastructureRef.Namespace != "" {
@@ -306,40 +358,12 @@ func (m *Mutator) DefaultCiliumCidrOnV18Upgrade(cluster capi.Cluster, currentRel
 
 	// Default the cilium pod cidr annotation if it's not set already.
 	if _, ok := cluster.Annotations[annotation.CiliumPodCidr]; !ok {
-		// We only default the cilium CIDR if this cluster:
-		// - is not using networkpools
-		// - is using the default pod cidr
-		// - cilium ENI mode is disabled
-
-		safeToDefault := true
-		if key.IsCiliumEniModeEnabled(cluster) {
-			safeToDefault = false
-		}
-
-		// Retrieve the `AWSCluster` CR related to this object.
-		awsCluster, err := aws.FetchAWSCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &cluster)
-		if apierrors.IsNotFound(err) {
-			// No AWS cluster exists, can't provide a default.
-			m.Log("level", "debug", "message", "AWSCluster not found, can't default cilium cidr")
-			safeToDefault = false
-		} else if err != nil {
+		podCidr, err := m.ciliumPodCidrDefault(cluster)
+		if err != nil {
 			return nil, microerror.Mask(err)
 		}
-
-		if awsCluster.Spec.Provider.Nodes.NetworkPool != "" {
-			// Networkpool in use, can't provide a sane default.
-			m.Log("level", "debug", "message", "Networkpool is set, can't default cilium cidr")
-			safeToDefault = false
-		}
-
-		if awsCluster.Spec.Provider.Pods.CIDRBlock != m.defaultAWSCNIPodCidr {
-			// Non default pod cidr, can't provide a sane default.
-			m.Log("level", "debug", "message", "Using not default cidr block, can't default cilium cidr")
-			safeToDefault = false
-		}
-
-		if safeToDefault {
-			annotations[annotation.CiliumPodCidr] = m.defaultCiliumPodCidr
+		if podCidr != "" {
+			annotations[annotation.CiliumPodCidr] = podCidr
 			changed = true
 		}
 	}
@@ -354,3 +378,143 @@ func (m *Mutator) DefaultCiliumCidrOnV18Upgrade(cluster capi.Cluster, currentRel
 	result = append(result, patch)
 	return result, nil
 }
+
+// upgradeScheduleTimeLayouts are the accepted formats of the scheduled upgrade
+// time besides the canonical RFC822 UTC format. All of them carry an explicit
+// offset or the UTC zone.
+var upgradeScheduleTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04Z07:00",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04 MST",
+	time.RFC822,
+	time.RFC822Z,
+	time.RFC1123,
+	time.RFC1123Z,
+}
+
+// MutateUpgradeScheduleTime normalizes the scheduled upgrade time to RFC822 in
+// UTC, the only format the upgrade scheduler understands, and records the
+// original value in a separate annotation.
+func (m *Mutator) MutateUpgradeScheduleTime(cluster capi.Cluster) []mutator.PatchOperation {
+	value, ok := cluster.GetAnnotations()[annotation.UpdateScheduleTargetTime]
+	if !ok {
+		return nil
+	}
+	normalized, ok := NormalizeUpgradeScheduleTime(value)
+	if !ok || normalized == value {
+		return nil
+	}
+
+	m.Log("level", "debug", "message", fmt.Sprintf("Normalizing upgrade time %s to %s", value, normalized))
+	var result []mutator.PatchOperation
+	patch := mutator.PatchAdd(fmt.Sprintf("/metadata/annotations/%s", aws.EscapeJSONPatchString(annotation.UpdateScheduleTargetTime)), normalized)
+	result = append(result, patch)
+	patch = mutator.PatchAdd(fmt.Sprintf("/metadata/annotations/%s", aws.EscapeJSONPatchString(aws.AnnotationUpdateScheduleTargetTimeOriginal)), value)
+	result = append(result, patch)
+	return result
+}
+
+// NormalizeUpgradeScheduleTime returns the time in RFC822 format and UTC time
+// zone. It returns false if the time can not be parsed or its time zone is
+// ambiguous.
+func NormalizeUpgradeScheduleTime(value string) (string, bool) {
+	t, ok := ParseUpgradeScheduleTime(value)
+	if !ok {
+		return "", false
+	}
+	return t.Format(time.RFC822), true
+}
+
+// ParseUpgradeScheduleTime parses the scheduled upgrade time in any of the
+// accepted formats and returns it in UTC. It returns false if the time can not
+// be parsed or its time zone is ambiguous.
+func ParseUpgradeScheduleTime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range upgradeScheduleTimeLayouts {
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		// Zone abbreviations other than UTC may be parsed with a fabricated zero
+		// offset, so they can not be converted safely.
+		if zone, _ := t.Zone(); strings.Contains(layout, "MST") && zone != "UTC" && zone != "GMT" {
+			return time.Time{}, false
+		}
+		return t.UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// ciliumPodCidrDefault returns the cilium pod cidr to default for the cluster,
+// or an empty string if no default can be provided.
+func (m *Mutator) ciliumPodCidrDefault(cluster capi.Cluster) (string, error) {
+	if key.IsCiliumEniModeEnabled(cluster) {
+		return "", nil
+	}
+
+	// Retrieve the `AWSCluster` CR related to this object.
+	awsCluster, err := aws.FetchAWSCluster(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, &cluster)
+	if apierrors.IsNotFound(err) {
+		// No AWS cluster exists, can't provide a default.
+		m.Log("level", "debug", "message", "AWSCluster not found, can't default cilium cidr")
+		return "", nil
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	if m.ciliumPodCidrSupernet != nil {
+		// Allocate a free network from the supernet, this also works for
+		// clusters using networkpools or a custom pod cidr.
+		podCidr, err := m.AllocateCiliumPodCidr(cluster)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+		m.Log("level", "debug", "message", fmt.Sprintf("Allocated cilium cidr %s", podCidr))
+		return podCidr, nil
+	}
+
+	// Without a supernet we only default the cilium CIDR if this cluster:
+	// - is not using networkpools
+	// - is using the default pod cidr
+	if awsCluster.Spec.Provider.Nodes.NetworkPool != "" {
+		// Networkpool in use, can't provide a sane default.
+		m.Log("level", "debug", "message", "Networkpool is set, can't default cilium cidr")
+		return "", nil
+	}
+	if awsCluster.Spec.Provider.Pods.CIDRBlock != m.defaultAWSCNIPodCidr {
+		// Non default pod cidr, can't provide a sane default.
+		m.Log("level", "debug", "message", "Using not default cidr block, can't default cilium cidr")
+		return "", nil
+	}
+
+	return m.defaultCiliumPodCidr, nil
+}
+
+// AllocateCiliumPodCidr returns the first network of the configured size within
+// the cilium pod cidr supernet which overlaps neither the configured network
+// ranges, nor any networkpool, nor the pod cidr of any AWSCluster, nor the
+// cilium pod cidr of any other cluster.
+func (m *Mutator) AllocateCiliumPodCidr(cluster capi.Cluster) (string, error) {
+	handler := &aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}
+
+	global, err := aws.GlobalReservations(m.dockerCIDR, m.ipamNetworkCIDR, m.kubernetesClusterIPRange)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	reservations, err := aws.FetchReservations(handler, global)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	network, err := reservations.Allocate(m.ciliumPodCidrSupernet, m.ciliumPodCidrSize, aws.CiliumPodCIDROwner(&cluster))
+	if aws.IsAllocationFailed(err) {
+		return "", microerror.Maskf(notAllowedError, "Unable to allocate a cilium pod CIDR, please set the %s annotation: %v", annotation.CiliumPodCidr, err)
+	} else if err != nil {
+		return "", microerror.Mask(err)
+	}
+
+	return network.String(), nil
+}
@@ -0,0 +1,92 @@
+package cluster
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/k8smetadata/pkg/annotation"
+	"github.com/giantswarm/micrologger/microloggertest"
+
+	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
+	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
+)
+
+func TestMutateUpgradeScheduleTime(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value string
+
+		expectedTime string
+	}{
+		{
+			name:         "case 0: canonical format is kept",
+			value:        "06 Jan 31 03:00 UTC",
+			expectedTime: "",
+		},
+		{
+			name:         "case 1: RFC3339 in UTC",
+			value:        "2031-01-06T03:00:00Z",
+			expectedTime: "06 Jan 31 03:00 UTC",
+		},
+		{
+			name:         "case 2: RFC3339 with offset",
+			value:        "2031-01-06T05:30:00+02:00",
+			expectedTime: "06 Jan 31 03:30 UTC",
+		},
+		{
+			name:         "case 3: date and time with offset",
+			value:        "2031-01-06 03:00-01:00",
+			expectedTime: "06 Jan 31 04:00 UTC",
+		},
+		{
+			name:         "case 4: RFC822 with numeric offset",
+			value:        "06 Jan 31 03:00 +0100",
+			expectedTime: "06 Jan 31 02:00 UTC",
+		},
+		{
+			name:         "case 5: RFC1123 in GMT",
+			value:        "Mon, 06 Jan 2031 03:00:00 GMT",
+			expectedTime: "06 Jan 31 03:00 UTC",
+		},
+		{
+			name:         "case 6: ambiguous zone abbreviation",
+			value:        "06 Jan 31 03:00 CET",
+			expectedTime: "",
+		},
+		{
+			name:         "case 7: no time zone",
+			value:        "2031-01-06 03:00",
+			expectedTime: "",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			mutate := &Mutator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+			cluster := unittest.DefaultCluster()
+			cluster.SetAnnotations(map[string]string{annotation.UpdateScheduleTargetTime: tc.value})
+
+			patch := mutate.MutateUpgradeScheduleTime(*cluster)
+
+			var updateTime, original string
+			for _, p := range patch {
+				switch p.Path {
+				case fmt.Sprintf("/metadata/annotations/%s", aws.EscapeJSONPatchString(annotation.UpdateScheduleTargetTime)):
+					updateTime = p.Value.(string)
+				case fmt.Sprintf("/metadata/annotations/%s", aws.EscapeJSONPatchString(aws.AnnotationUpdateScheduleTargetTimeOriginal)):
+					original = p.Value.(string)
+				}
+			}
+			if updateTime != tc.expectedTime {
+				t.Fatalf("%s: expected %#q, got %#q", tc.name, tc.expectedTime, updateTime)
+			}
+			if tc.expectedTime != "" && original != tc.value {
+				t.Fatalf("%s: expected original %#q, got %#q", tc.name, tc.value, original)
+			}
+		})
+	}
+}
@@ -3,36 +3,46 @@ package cluster
 import (
 	"context"
 	"fmt"
-	"net"
 	"time"
 
 	"github.com/blang/semver/v4"
-	kustomizev1beta2 "github.com/fluxcd/kustomize-controller/api/v1beta2"
 	infrastructurev1alpha3 "github.com/giantswarm/apiextensions/v6/pkg/apis/infrastructure/v1alpha3"
 	"github.com/giantswarm/k8sclient/v7/pkg/k8sclient"
 	"github.com/giantswarm/k8smetadata/pkg/annotation"
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
-	authenticationv1 "k8s.io/api/authentication/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/giantswarm/aws-admission-controller/v4/config"
 	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/cidr"
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/key"
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/mutator"
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/validator"
 )
 
+// ciliumPrefixRules are the allowed sizes of the Cilium pod CIDRs. The IPv6
+// range is used for dual-stack pod networks.
+var ciliumPrefixRules = cidr.PrefixRules{
+	cidr.IPv4: {Min: 0, Max: 18},
+	cidr.IPv6: {Min: 0, Max: 104},
+}
+
 type Validator struct {
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
 
-	ipamCidrBlock    string
-	restrictedGroups []string
+	dockerCIDR               string
+	gitopsGuard              aws.GitopsGuard
+	ipamCidrBlock            string
+	kubernetesClusterIPRange string
+	restrictedIdentities     aws.RestrictedIdentities
+	releaseGraph             aws.ReleaseGraph
+	upgradeChecks            []aws.UpgradeCheck
 }
 
 func NewValidator(config config.Config) (*Validator, error) {
@@ -47,16 +57,24 @@ func NewValidator(config config.Config) (*Validator, error) {
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
 
-		ipamCidrBlock: config.IPAMNetworkCIDR,
-		restrictedGroups: []string{
-			config.AdminGroup,
-		},
+		dockerCIDR:               config.DockerCIDR,
+		gitopsGuard:              config.Policy.GitopsGuard,
+		ipamCidrBlock:            config.IPAMNetworkCIDR,
+		kubernetesClusterIPRange: config.KubernetesClusterIPRange,
+		restrictedIdentities:     config.Policy.RestrictedIdentities,
+		releaseGraph:             config.Policy.ReleaseGraph,
+		upgradeChecks:            aws.DefaultUpgradeChecks(),
 	}
 
 	return v, nil
 }
 
 func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error) {
+	// Deletion protection also applies to dry runs so that they report what
+	// the actual deletion would do.
+	if request.Operation == admissionv1.Delete {
+		return v.ValidateDelete(request)
+	}
 	if request.DryRun != nil && *request.DryRun {
 		return true, nil
 	}
@@ -69,6 +87,28 @@ func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error
 	return true, nil
 }
 
+func (v *Validator) ValidateDelete(request *admissionv1.AdmissionRequest) (bool, error) {
+	var err error
+
+	// Parse the object to be deleted
+	cluster := &capi.Cluster{}
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, cluster); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse cluster: %v", err)
+	}
+
+	err = aws.ValidateDeletionProtection(cluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateGitopsPaused(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, v.gitopsGuard, "Cluster", cluster, nil)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return true, nil
+}
+
 func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool, error) {
 	var err error
 
@@ -83,7 +123,12 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Mask(err)
 	}
 
-	err = aws.ValidateOrgNamespace(cluster)
+	err = aws.ValidateNamespacePlacement(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "Cluster", nil, cluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConstraints("Cluster", nil, cluster)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
@@ -103,6 +148,16 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Mask(err)
 	}
 
+	err = v.ValidateCiliumEniMode(cluster, nil)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.MaintenanceAnnotationsValid(nil, cluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
 	return true, nil
 }
 
@@ -127,7 +182,7 @@ func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool,
 		return true, nil
 	}
 
-	// Block v18 to v19 upgrades for gitops-managed clusters.
+	// Block guarded changes of gitops-managed clusters.
 	err = v.EnsureGitopsPaused(cluster, oldCluster)
 	if err != nil {
 		return false, microerror.Mask(err)
@@ -148,11 +203,36 @@ func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Mask(err)
 	}
 
-	if v.isAdmin(request.UserInfo) || v.isInRestrictedGroup(request.UserInfo) {
-		err = v.ClusterStatusValid(oldCluster, cluster)
+	err = v.MaintenanceAnnotationsValid(oldCluster, cluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = v.ClusterAnnotationUpgradeScheduleIsValid(cluster, oldCluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	// Release changes of restricted identities are validated. The status
+	// checks can only be bypassed by restricted identities.
+	if v.restrictedIdentities.ReleaseChange.Matches(request.UserInfo) {
+		if !v.restrictedIdentities.StatusBypass.Matches(request.UserInfo) {
+			err = v.ClusterStatusValid(oldCluster, cluster)
+			if err != nil {
+				return false, microerror.Mask(err)
+			}
+			err = v.UpgradeReadinessValid(oldCluster, cluster)
+			if err != nil {
+				return false, microerror.Mask(err)
+			}
+		}
+		err = v.ReleaseVersionValid(oldCluster, cluster)
 		if err != nil {
 			return false, microerror.Mask(err)
 		}
+	}
+
+	if v.restrictedIdentities.LabelEdit.Matches(request.UserInfo) {
 		err = v.ClusterLabelKeysValid(oldCluster, cluster)
 		if err != nil {
 			return false, microerror.Mask(err)
@@ -161,10 +241,21 @@ func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool,
 		if err != nil {
 			return false, microerror.Mask(err)
 		}
-		err = v.ReleaseVersionValid(oldCluster, cluster)
-		if err != nil {
-			return false, microerror.Mask(err)
-		}
+	}
+
+	err = aws.ValidateLabelConsistency(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "Cluster", oldCluster, cluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateNamespacePlacement(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "Cluster", oldCluster, cluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConstraints("Cluster", oldCluster, cluster)
+	if err != nil {
+		return false, microerror.Mask(err)
 	}
 
 	err = v.ValidateCiliumIpamMode(cluster)
@@ -172,6 +263,11 @@ func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Mask(err)
 	}
 
+	err = v.ValidateCiliumEniMode(cluster, oldCluster)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
 	err = v.ValidateCiliumIpamModeUnchanged(oldCluster, cluster)
 	if err != nil {
 		return false, microerror.Mask(err)
@@ -191,7 +287,7 @@ func (v *Validator) ClusterAnnotationUpgradeTimeIsValid(cluster *capi.Cluster, o
 		if !UpgradeScheduleTimeIsValid(updateTime) {
 			v.logger.Log("level", "error", "message", "upgrade time is not valid")
 			return microerror.Maskf(notAllowedError,
-				fmt.Sprintf("Cluster annotation '%s' value '%s' is not valid. Value must be in RFC822 format and UTC time zone (e.g. 30 Jan 21 15:04 UTC) and should be a date 16 mins - 6months in the future.",
+				fmt.Sprintf("Cluster annotation '%s' value '%s' is not valid. Value must be in RFC822 format and UTC time zone (e.g. 30 Jan 21 15:04 UTC) or in RFC3339 format with time zone offset (e.g. 2021-01-30T16:04:00+01:00) and should be a date 16 mins - 6months in the future.",
 					annotation.UpdateScheduleTargetTime,
 					updateTime),
 			)
@@ -201,13 +297,9 @@ func (v *Validator) ClusterAnnotationUpgradeTimeIsValid(cluster *capi.Cluster, o
 }
 
 func UpgradeScheduleTimeIsValid(updateTime string) bool {
-	// parse time
-	t, err := time.Parse(time.RFC822, updateTime)
-	if err != nil {
-		return false
-	}
-	// check whether it is UTC
-	if t.Location().String() != "UTC" {
+	// parse time, the time zone has to be unambiguous
+	t, ok := ParseUpgradeScheduleTime(updateTime)
+	if !ok {
 		return false
 	}
 	// time already passed or is less than 16 minutes in the future
@@ -239,6 +331,81 @@ func (v *Validator) ClusterAnnotationUpgradeReleaseIsValid(cluster *capi.Cluster
 	return nil
 }
 
+// MaintenanceAnnotationsValid validates the maintenance window, freeze and
+// upgrade spacing annotations. On update they are only validated when one of
+// them changes. oldCluster is nil on create.
+func (v *Validator) MaintenanceAnnotationsValid(oldCluster *capi.Cluster, cluster *capi.Cluster) error {
+	maintenanceAnnotations := []string{aws.AnnotationMaintenanceWindows, aws.AnnotationMaintenanceFreezes, aws.AnnotationMaintenanceUpgradeSpacing}
+	if oldCluster != nil {
+		changed := false
+		for _, a := range maintenanceAnnotations {
+			if oldCluster.GetAnnotations()[a] != cluster.GetAnnotations()[a] {
+				changed = true
+			}
+		}
+		if !changed {
+			return nil
+		}
+	}
+
+	_, err := aws.ParseMaintenancePolicy(cluster.GetAnnotations())
+	if err != nil {
+		return microerror.Maskf(notAllowedError, "Cluster %s has invalid maintenance annotations: %v", cluster.GetName(), err)
+	}
+	return nil
+}
+
+// ClusterAnnotationUpgradeScheduleIsValid validates that the scheduled upgrade
+// target release and time are set together, that the time is within the
+// maintenance windows and outside the freeze periods of the cluster and that
+// it does not overlap with scheduled upgrades of other clusters.
+func (v *Validator) ClusterAnnotationUpgradeScheduleIsValid(cluster *capi.Cluster, oldCluster *capi.Cluster) error {
+	targetRelease, hasRelease := cluster.GetAnnotations()[annotation.UpdateScheduleTargetRelease]
+	targetTime, hasTime := cluster.GetAnnotations()[annotation.UpdateScheduleTargetTime]
+	if !hasRelease && !hasTime {
+		return nil
+	}
+	if oldCluster.GetAnnotations()[annotation.UpdateScheduleTargetRelease] == targetRelease &&
+		oldCluster.GetAnnotations()[annotation.UpdateScheduleTargetTime] == targetTime {
+		return nil
+	}
+
+	if hasRelease != hasTime {
+		return microerror.Maskf(notAllowedError,
+			fmt.Sprintf("Cluster annotations '%s' and '%s' have to be set together.",
+				annotation.UpdateScheduleTargetRelease,
+				annotation.UpdateScheduleTargetTime),
+		)
+	}
+
+	t, ok := ParseUpgradeScheduleTime(targetTime)
+	if !ok {
+		// The format is validated by ClusterAnnotationUpgradeTimeIsValid.
+		return nil
+	}
+
+	handler := &aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}
+	policy, err := aws.FetchMaintenancePolicy(handler, cluster)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	err = policy.Allows(t)
+	if err != nil {
+		return microerror.Maskf(notAllowedError,
+			fmt.Sprintf("Cluster annotation '%s' value '%s' is not valid. %v", annotation.UpdateScheduleTargetTime, targetTime, err),
+		)
+	}
+
+	err = aws.ValidateScheduledUpgradeSpacing(handler, cluster, t, policy.Spacing())
+	if err != nil {
+		return microerror.Maskf(notAllowedError,
+			fmt.Sprintf("Cluster annotation '%s' value '%s' is not valid. %v", annotation.UpdateScheduleTargetTime, targetTime, err),
+		)
+	}
+
+	return nil
+}
+
 func (v *Validator) UpgradeScheduleReleaseIsValid(targetRelease string, currentRelease string) error {
 	// parse target version
 	t, err := semver.New(targetRelease)
@@ -259,7 +426,17 @@ func (v *Validator) UpgradeScheduleReleaseIsValid(targetRelease string, currentR
 	if t.LE(*c) {
 		return microerror.Maskf(notAllowedError, "Upgrade target release version has to be above current release version.")
 	}
-	return nil
+	return v.UpgradePathValid(*c, *t)
+}
+
+// UpgradePathValid validates the upgrade between two releases against the
+// release graph from the configuration and the Release CR annotations.
+func (v *Validator) UpgradePathValid(from semver.Version, to semver.Version) error {
+	graph, err := aws.FetchReleaseGraph(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, v.releaseGraph)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	return graph.ValidateUpgrade(from, to)
 }
 
 func (v *Validator) Cilium(cluster *capi.Cluster, oldCluster *capi.Cluster) error {
@@ -296,53 +473,45 @@ func (v *Validator) Cilium(cluster *capi.Cluster, oldCluster *capi.Cluster) erro
 	}
 
 	// Annotation exists, validate it.
-	_, ciliumIPNet, err := net.ParseCIDR(podCidr)
+	ciliumNets, err := cidr.ParseList(podCidr)
 	if err != nil {
-		return microerror.Mask(err)
+		return microerror.Maskf(notAllowedError,
+			fmt.Sprintf("The CIDR from annotation `%s` is not valid: %v", annotation.CiliumPodCidr, err),
+		)
 	}
-	prefix, _ := ciliumIPNet.Mask.Size()
-	if prefix > 18 {
+	err = ciliumPrefixRules.ValidateList(ciliumNets)
+	if err != nil {
 		return microerror.Maskf(notAllowedError,
-			fmt.Sprintf("The CIDR from annotation `%s` is not valid, please specify a network mask which is at least `/18` or bigger, e.g. `10.0.0.0/15`", annotation.CiliumPodCidr),
+			fmt.Sprintf("The CIDR from annotation `%s` is not valid, please specify a network mask which is at least `/18` or bigger for IPv4, e.g. `10.0.0.0/15`, or `/104` for IPv6: %v", annotation.CiliumPodCidr, err),
 		)
 	}
 
-	awsCluster, err := aws.FetchAWSCluster(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, cluster)
+	global, err := aws.GlobalReservations(v.dockerCIDR, v.ipamCidrBlock, v.kubernetesClusterIPRange)
 	if err != nil {
 		return microerror.Mask(err)
 	}
-
-	_, awsPodIPNet, err := net.ParseCIDR(awsCluster.Spec.Provider.Pods.CIDRBlock)
+	handler := &aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}
+	reservations, err := aws.FetchReservations(handler, global)
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
-	var ipamCidr string
-	{
-		if awsCluster.Spec.Provider.Nodes.NetworkPool != "" {
-			// Cluster is using a custom network CIDR for nodes, we need to retrieve the NetworkPool CR to know it.
-			np := infrastructurev1alpha3.NetworkPool{}
-			err = v.k8sClient.CtrlClient().Get(context.Background(), client.ObjectKey{Namespace: awsCluster.Namespace, Name: awsCluster.Spec.Provider.Nodes.NetworkPool}, &np)
-			if err != nil {
-				return microerror.Mask(err)
-			}
-
-			ipamCidr = np.Spec.CIDRBlock
-		} else {
-			ipamCidr = v.ipamCidrBlock
-		}
-	}
-
-	_, ipamIPNet, err := net.ParseCIDR(ipamCidr)
-	if err != nil {
+	// The NetworkPool of the nodes is only known once the AWSCluster exists.
+	var networkPool string
+	awsCluster, err := aws.FetchAWSCluster(handler, cluster)
+	if err == nil {
+		networkPool = awsCluster.Spec.Provider.Nodes.NetworkPool
+	} else if !aws.IsNotFound(err) {
 		return microerror.Mask(err)
 	}
 
-	if intersect(ciliumIPNet, awsPodIPNet) || intersect(ciliumIPNet, ipamIPNet) {
-		return microerror.Maskf(notAllowedError,
-			fmt.Sprintf("The CIDR from annotation `%s` intersects with the current CIDRs `%s`, `%s`, please specify a different CIDR", annotation.CiliumPodCidr, awsCluster.Spec.Provider.Pods.CIDRBlock, ipamCidr),
-		)
-
+	for _, ciliumNet := range ciliumNets {
+		conflict, ok := reservations.Conflict(aws.Reservation{ClusterID: aws.ClusterID(cluster), NetworkPool: networkPool, Network: ciliumNet, Owner: aws.CiliumPodCIDROwner(cluster)})
+		if ok {
+			return microerror.Maskf(notAllowedError,
+				fmt.Sprintf("The CIDR from annotation `%s` intersects with the %s `%s`, please specify a different CIDR", annotation.CiliumPodCidr, conflict.Owner, conflict.Network.String()),
+			)
+		}
 	}
 
 	return nil
@@ -365,6 +534,62 @@ func (v *Validator) ValidateCiliumIpamMode(cluster *capi.Cluster) error {
 	)
 }
 
+// ValidateCiliumEniMode validates the prerequisites of the Cilium ENI IPAM
+// mode. In ENI mode pods get their IPs from the VPC subnets, so the cluster
+// must neither use a NetworkPool, nor AWS CNI IP allocation settings, nor a
+// Cilium pod CIDR. On update, clusters already in ENI mode are only validated
+// again when their release or Cilium pod CIDR changes.
+func (v *Validator) ValidateCiliumEniMode(cluster *capi.Cluster, oldCluster *capi.Cluster) error {
+	if !key.IsCiliumEniModeEnabled(*cluster) {
+		return nil
+	}
+	if oldCluster != nil && key.IsCiliumEniModeEnabled(*oldCluster) &&
+		key.Release(oldCluster) == key.Release(cluster) &&
+		oldCluster.GetAnnotations()[annotation.CiliumPodCidr] == cluster.GetAnnotations()[annotation.CiliumPodCidr] {
+		return nil
+	}
+
+	releaseVersion, err := semver.New(key.Release(cluster))
+	if err != nil {
+		return microerror.Maskf(parsingFailedError, "unable to parse release version from Cluster")
+	}
+	firstEniModeRelease, _ := semver.New(aws.FirstCiliumEniModeRelease)
+	if releaseVersion.LT(*firstEniModeRelease) {
+		return microerror.Maskf(notAllowedError,
+			fmt.Sprintf("Cilium IPAM mode %q requires release v%s or newer, but the Cluster uses release v%s.", annotation.CiliumIpamModeENI, aws.FirstCiliumEniModeRelease, releaseVersion),
+		)
+	}
+
+	if podCidr := cluster.GetAnnotations()[annotation.CiliumPodCidr]; podCidr != "" {
+		return microerror.Maskf(notAllowedError,
+			fmt.Sprintf("The annotation `%s` (%s) can not be used with Cilium IPAM mode %q, pods get their IPs from the VPC subnets.", annotation.CiliumPodCidr, podCidr, annotation.CiliumIpamModeENI),
+		)
+	}
+
+	awsCluster, err := aws.FetchAWSCluster(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, cluster)
+	if errors.IsNotFound(err) {
+		// There is no AWSCluster to validate against yet.
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if awsCluster.Spec.Provider.Nodes.NetworkPool != "" {
+		return microerror.Maskf(notAllowedError,
+			fmt.Sprintf("Cilium IPAM mode %q can not be used with NetworkPool %s configured in AWSCluster %s.", annotation.CiliumIpamModeENI, awsCluster.Spec.Provider.Nodes.NetworkPool, awsCluster.GetName()),
+		)
+	}
+	for _, a := range []string{annotation.AWSCNIPrefixDelegation, annotation.AWSCNIWarmIPTarget, annotation.AWSCNIMinimumIPTarget} {
+		if _, ok := awsCluster.GetAnnotations()[a]; ok {
+			return microerror.Maskf(notAllowedError,
+				fmt.Sprintf("Cilium IPAM mode %q can not be used with the annotation `%s` on AWSCluster %s, please remove it.", annotation.CiliumIpamModeENI, a, awsCluster.GetName()),
+			)
+		}
+	}
+
+	return nil
+}
+
 func (v *Validator) ClusterLabelKeysValid(oldCluster *capi.Cluster, newCluster *capi.Cluster) error {
 	return aws.ValidateLabelKeys(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, oldCluster, newCluster)
 }
@@ -393,6 +618,15 @@ func (v *Validator) ClusterStatusValid(oldCluster *capi.Cluster, newCluster *cap
 	return nil
 }
 
+// UpgradeReadinessValid runs the pre-upgrade checks when the release version
+// label changes.
+func (v *Validator) UpgradeReadinessValid(oldCluster *capi.Cluster, newCluster *capi.Cluster) error {
+	if key.Release(newCluster) == key.Release(oldCluster) {
+		return nil
+	}
+	return aws.ValidateUpgradeReadiness(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, oldCluster, newCluster, v.upgradeChecks)
+}
+
 func (v *Validator) ReleaseVersionValid(oldCluster *capi.Cluster, newCluster *capi.Cluster) error {
 	var err error
 
@@ -426,31 +660,7 @@ func (v *Validator) ReleaseVersionValid(oldCluster *capi.Cluster, newCluster *ca
 		return microerror.Maskf(notAllowedError, "Release %v is deprecated.", release.GetName())
 	}
 
-	return nil
-}
-
-func intersect(n1, n2 *net.IPNet) bool {
-	return n2.Contains(n1.IP) || n1.Contains(n2.IP)
-}
-
-func (v *Validator) isAdmin(userInfo authenticationv1.UserInfo) bool {
-	for _, u := range aws.ValidLabelAdmins() {
-		if u == userInfo.Username {
-			return true
-		}
-	}
-	return false
-}
-
-func (v *Validator) isInRestrictedGroup(userInfo authenticationv1.UserInfo) bool {
-	for _, r := range v.restrictedGroups {
-		for _, u := range userInfo.Groups {
-			if r == u {
-				return true
-			}
-		}
-	}
-	return false
+	return v.UpgradePathValid(*oldReleaseVersion, *releaseVersion)
 }
 
 func (v *Validator) ClusterExists(obj metav1.Object) error {
@@ -471,35 +681,15 @@ func (v *Validator) ClusterExists(obj metav1.Object) error {
 	return nil
 }
 
+// EnsureGitopsPaused returns an error if a change matched by the gitops guard
+// is made to a cluster whose GitOps owner is not suspended.
 func (v *Validator) EnsureGitopsPaused(cluster *capi.Cluster, oldCluster *capi.Cluster) error {
-	targetRelease, err := semver.New(key.Release(cluster))
+	violation, err := v.gitopsGuard.Violation(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "Cluster", oldCluster, cluster)
 	if err != nil {
-		return err
-	}
-
-	currentRelease, err := semver.New(key.Release(oldCluster))
-	if err != nil {
-		return err
+		return microerror.Mask(err)
 	}
-	if aws.IsPreCiliumRelease(currentRelease) && aws.IsCiliumRelease(targetRelease) {
-		// We are trying to upgrade from v18 to v19.
-
-		ok := key.FluxKustomizationObjectKey(cluster)
-		if ok != nil {
-			kust := kustomizev1beta2.Kustomization{}
-			err = v.k8sClient.CtrlClient().Get(context.Background(), *ok, &kust)
-			if errors.IsNotFound(err) {
-				// Labels are present but Kustomization was not found. Might be running on customer infra. Don't want to block upgrade.
-				return nil
-			} else if err != nil {
-				return err
-			}
-
-			if !kust.Spec.Suspend {
-				return microerror.Maskf(notAllowedError, fmt.Sprintf("Cluster %s/%s is managed by gitops but Kustomization %s/%s is not suspended", cluster.Namespace, cluster.Name, ok.Namespace, ok.Name))
-			}
-		}
-
+	if violation != "" {
+		return microerror.Maskf(notAllowedError, "%s", violation)
 	}
 	return nil
 }
@@ -551,3 +741,7 @@ func (v *Validator) Log(keyVals ...interface{}) {
 func (v *Validator) Resource() string {
 	return "cluster"
 }
+
+func (v *Validator) GroupVersionKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{aws.ClusterGVK}
+}
@@ -15,7 +15,9 @@ import (
 	"github.com/giantswarm/micrologger/microloggertest"
 	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 
+	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
 	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
 )
 
@@ -178,6 +180,16 @@ func Test_UpgradeTimeIsValid(t *testing.T) {
 			noChange: true,
 			valid:    true,
 		},
+		{
+			name:  "case 9: 2 hours from now in RFC3339 format with offset",
+			value: time.Now().In(time.FixedZone("", 2*60*60)).Add(2 * time.Hour).Format(time.RFC3339),
+			valid: true,
+		},
+		{
+			name:  "case 10: 2 hours from now in RFC822 format with ambiguous time zone",
+			value: strings.Replace(time.Now().UTC().Add(2*time.Hour).Format(time.RFC822), "UTC", "CET", 1),
+			valid: false,
+		},
 	}
 
 	for i, tc := range testCases {
@@ -206,6 +218,244 @@ func Test_UpgradeTimeIsValid(t *testing.T) {
 	}
 }
 
+func TestUpgradeScheduleIsValid(t *testing.T) {
+	testCases := []struct {
+		name                    string
+		oldAnnotations          map[string]string
+		annotations             map[string]string
+		organizationAnnotations map[string]string
+		otherScheduledTime      string
+
+		valid bool
+	}{
+		{
+			// No scheduled upgrade
+			name:  "case 0",
+			valid: true,
+		},
+		{
+			// Release and time set together
+			name: "case 1",
+			annotations: map[string]string{
+				annotation.UpdateScheduleTargetRelease: "15.1.0",
+				annotation.UpdateScheduleTargetTime:    "06 Jan 31 03:00 UTC",
+			},
+			valid: true,
+		},
+		{
+			// Release without time
+			name: "case 2",
+			annotations: map[string]string{
+				annotation.UpdateScheduleTargetRelease: "15.1.0",
+			},
+			valid: false,
+		},
+		{
+			// Time without release
+			name: "case 3",
+			annotations: map[string]string{
+				annotation.UpdateScheduleTargetTime: "06 Jan 31 03:00 UTC",
+			},
+			valid: false,
+		},
+		{
+			// Unchanged incomplete schedule
+			name: "case 4",
+			oldAnnotations: map[string]string{
+				annotation.UpdateScheduleTargetRelease: "15.1.0",
+			},
+			annotations: map[string]string{
+				annotation.UpdateScheduleTargetRelease: "15.1.0",
+			},
+			valid: true,
+		},
+		{
+			// Outside of the organization maintenance windows
+			name: "case 5",
+			annotations: map[string]string{
+				annotation.UpdateScheduleTargetRelease: "15.1.0",
+				annotation.UpdateScheduleTargetTime:    "04 Jan 31 03:00 UTC",
+			},
+			organizationAnnotations: map[string]string{aws.AnnotationMaintenanceWindows: "Mon-Fri 02:00-06:00"},
+			valid:                   false,
+		},
+		{
+			// Cluster maintenance windows replace organization windows
+			name: "case 6",
+			annotations: map[string]string{
+				annotation.UpdateScheduleTargetRelease: "15.1.0",
+				annotation.UpdateScheduleTargetTime:    "04 Jan 31 03:00 UTC",
+				aws.AnnotationMaintenanceWindows:       "Sat,Sun 00:00-24:00",
+			},
+			organizationAnnotations: map[string]string{aws.AnnotationMaintenanceWindows: "Mon-Fri 02:00-06:00"},
+			valid:                   true,
+		},
+		{
+			// Within an organization freeze period
+			name: "case 7",
+			annotations: map[string]string{
+				annotation.UpdateScheduleTargetRelease: "15.1.0",
+				annotation.UpdateScheduleTargetTime:    "06 Jan 31 03:00 UTC",
+			},
+			organizationAnnotations: map[string]string{aws.AnnotationMaintenanceFreezes: "12-20/01-06"},
+			valid:                   false,
+		},
+		{
+			// Overlapping with the scheduled upgrade of another cluster
+			name: "case 8",
+			annotations: map[string]string{
+				annotation.UpdateScheduleTargetRelease: "15.1.0",
+				annotation.UpdateScheduleTargetTime:    "06 Jan 31 03:00 UTC",
+			},
+			otherScheduledTime: "06 Jan 31 04:00 UTC",
+			valid:              false,
+		},
+		{
+			// Far enough from the scheduled upgrade of another cluster
+			name: "case 9",
+			annotations: map[string]string{
+				annotation.UpdateScheduleTargetRelease: "15.1.0",
+				annotation.UpdateScheduleTargetTime:    "06 Jan 31 03:00 UTC",
+			},
+			otherScheduledTime: "06 Jan 31 05:00 UTC",
+			valid:              true,
+		},
+		{
+			// Within the organization upgrade spacing of another cluster
+			name: "case 10",
+			annotations: map[string]string{
+				annotation.UpdateScheduleTargetRelease: "15.1.0",
+				annotation.UpdateScheduleTargetTime:    "06 Jan 31 03:00 UTC",
+			},
+			organizationAnnotations: map[string]string{aws.AnnotationMaintenanceUpgradeSpacing: "30m"},
+			otherScheduledTime:      "06 Jan 31 04:00 UTC",
+			valid:                   true,
+		},
+		{
+			// Cluster upgrade spacing replaces organization upgrade spacing
+			name: "case 11",
+			annotations: map[string]string{
+				annotation.UpdateScheduleTargetRelease:  "15.1.0",
+				annotation.UpdateScheduleTargetTime:     "06 Jan 31 03:00 UTC",
+				aws.AnnotationMaintenanceUpgradeSpacing: "3h",
+			},
+			organizationAnnotations: map[string]string{aws.AnnotationMaintenanceUpgradeSpacing: "30m"},
+			otherScheduledTime:      "06 Jan 31 05:00 UTC",
+			valid:                   false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			v := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+			}
+
+			organization := unittest.DefaultOrganization()
+			organization.SetAnnotations(tc.organizationAnnotations)
+			err := fakeK8sClient.CtrlClient().Create(context.Background(), organization)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.otherScheduledTime != "" {
+				other := unittest.DefaultCluster()
+				other.SetName("other")
+				other.SetAnnotations(map[string]string{
+					annotation.UpdateScheduleTargetRelease: "15.1.0",
+					annotation.UpdateScheduleTargetTime:    tc.otherScheduledTime,
+				})
+				err = fakeK8sClient.CtrlClient().Create(context.Background(), other)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			oldCluster := unittest.DefaultCluster()
+			oldCluster.SetAnnotations(tc.oldAnnotations)
+			cluster := unittest.DefaultCluster()
+			cluster.SetAnnotations(tc.annotations)
+
+			err = v.ClusterAnnotationUpgradeScheduleIsValid(cluster, oldCluster)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && !IsNotAllowed(err) {
+				t.Fatalf("expected not allowed error but got %v", err)
+			}
+		})
+	}
+}
+
+func TestMaintenanceAnnotationsValid(t *testing.T) {
+	testCases := []struct {
+		name           string
+		update         bool
+		oldAnnotations map[string]string
+		annotations    map[string]string
+
+		valid bool
+	}{
+		{
+			// Valid maintenance annotations on create
+			name: "case 0",
+			annotations: map[string]string{
+				aws.AnnotationMaintenanceWindows:        "Mon-Fri 02:00-06:00",
+				aws.AnnotationMaintenanceFreezes:        "12-20/01-06",
+				aws.AnnotationMaintenanceUpgradeSpacing: "1h",
+			},
+			valid: true,
+		},
+		{
+			// Invalid maintenance window on create
+			name:        "case 1",
+			annotations: map[string]string{aws.AnnotationMaintenanceWindows: "Monday 02:00-06:00"},
+			valid:       false,
+		},
+		{
+			// Invalid freeze period on update
+			name:        "case 2",
+			update:      true,
+			annotations: map[string]string{aws.AnnotationMaintenanceFreezes: "2031-01-06"},
+			valid:       false,
+		},
+		{
+			// Unchanged invalid maintenance window on update
+			name:           "case 3",
+			update:         true,
+			oldAnnotations: map[string]string{aws.AnnotationMaintenanceWindows: "Monday 02:00-06:00"},
+			annotations:    map[string]string{aws.AnnotationMaintenanceWindows: "Monday 02:00-06:00"},
+			valid:          true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			v := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			var oldCluster *capi.Cluster
+			if tc.update {
+				oldCluster = unittest.DefaultCluster()
+				oldCluster.SetAnnotations(tc.oldAnnotations)
+			}
+			cluster := unittest.DefaultCluster()
+			cluster.SetAnnotations(tc.annotations)
+
+			err := v.MaintenanceAnnotationsValid(oldCluster, cluster)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && !IsNotAllowed(err) {
+				t.Fatalf("expected not allowed error but got %v", err)
+			}
+		})
+	}
+}
+
 func TestValidateReleaseVersion(t *testing.T) {
 	testCases := []struct {
 		ctx  context.Context
@@ -527,12 +777,14 @@ func TestCilium(t *testing.T) {
 		ctx  context.Context
 		name string
 
-		currentRelease string
-		targetRelease  string
-		ciliumCidr     string
-		ipamCidrBlock  string
-		podCidrBlock   string
-		err            error
+		currentRelease  string
+		targetRelease   string
+		ciliumCidr      string
+		ipamCidrBlock   string
+		podCidrBlock    string
+		networkPool     string
+		networkPoolUsed bool
+		err             error
 	}{
 		{
 			// CNI CIDR is not allowed too small.
@@ -594,6 +846,33 @@ func TestCilium(t *testing.T) {
 			podCidrBlock:   "10.0.0.0/16",
 			err:            nil,
 		},
+		{
+			// CNI CIDR overlaps a NetworkPool of other clusters
+			name: "case 5",
+			ctx:  context.Background(),
+
+			currentRelease: "19.0.0",
+			targetRelease:  "19.1.0",
+			ciliumCidr:     "192.168.0.0/16",
+			ipamCidrBlock:  "10.1.0.0/16",
+			podCidrBlock:   "10.2.0.0/16",
+			networkPool:    "192.168.1.0/24",
+			err:            nil,
+		},
+		{
+			// CNI CIDR overlaps the NetworkPool of the cluster
+			name: "case 6",
+			ctx:  context.Background(),
+
+			currentRelease:  "19.0.0",
+			targetRelease:   "19.1.0",
+			ciliumCidr:      "192.168.0.0/16",
+			ipamCidrBlock:   "10.1.0.0/16",
+			podCidrBlock:    "10.2.0.0/16",
+			networkPool:     "192.168.1.0/24",
+			networkPoolUsed: true,
+			err:             notAllowedError,
+		},
 	}
 	for i, tc := range testCases {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
@@ -610,6 +889,16 @@ func TestCilium(t *testing.T) {
 			// run admission request to default AWSCluster Pod CIDR
 			awsCluster := unittest.DefaultAWSCluster()
 			awsCluster.Spec.Provider.Pods.CIDRBlock = tc.podCidrBlock
+			if tc.networkPool != "" {
+				networkPool := unittest.DefaultNetworkPool(tc.networkPool)
+				err = fakeK8sClient.CtrlClient().Create(context.Background(), networkPool)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tc.networkPoolUsed {
+					awsCluster.Spec.Provider.Nodes.NetworkPool = networkPool.GetName()
+				}
+			}
 			err = fakeK8sClient.CtrlClient().Create(context.Background(), awsCluster)
 			if err != nil {
 				t.Fatal(err)
@@ -775,6 +1064,27 @@ func TestValidator_EnsureGitopsPaused(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			name: "case 6: major upgrade after v19, flux not suspended",
+			ctx:  context.Background(),
+
+			currentRelease: "19.3.0",
+			targetRelease:  "20.0.0",
+			kustomization: &v1beta2.Kustomization{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "kust1",
+					Namespace: "default",
+				},
+				Spec: v1beta2.KustomizationSpec{
+					Suspend: false,
+				},
+			},
+			labels: map[string]string{
+				"kustomize.toolkit.fluxcd.io/name":      "kust1",
+				"kustomize.toolkit.fluxcd.io/namespace": "default",
+			},
+			err: nil,
+		},
 	}
 	for i, tc := range testCases {
 		t.Run(strconv.Itoa(i), func(t *testing.T) {
@@ -802,6 +1112,8 @@ func TestValidator_EnsureGitopsPaused(t *testing.T) {
 			validate := &Validator{
 				k8sClient: fakeK8sClient,
 				logger:    microloggertest.New(),
+
+				gitopsGuard: aws.DefaultGitopsGuard(),
 			}
 
 			err = validate.EnsureGitopsPaused(cluster, oldCluster)
@@ -876,6 +1188,145 @@ func Test_CiliumIpamMode(t *testing.T) {
 	}
 }
 
+func TestValidateCiliumEniMode(t *testing.T) {
+	eni := map[string]string{annotation.CiliumIpamModeAnnotation: annotation.CiliumIpamModeENI}
+
+	testCases := []struct {
+		name                  string
+		oldAnnotations        map[string]string
+		annotations           map[string]string
+		release               string
+		networkPool           string
+		awsClusterAnnotations map[string]string
+		noAWSCluster          bool
+		update                bool
+
+		err error
+	}{
+		{
+			// Kubernetes IPAM mode
+			name:        "case 0",
+			annotations: map[string]string{annotation.CiliumIpamModeAnnotation: annotation.CiliumIpamModeKubernetes},
+			release:     "19.0.0",
+			networkPool: "np1",
+		},
+		{
+			// ENI mode without conflicts
+			name:        "case 1",
+			annotations: eni,
+			release:     "19.0.0",
+		},
+		{
+			// ENI mode configured ahead of the upgrade to Cilium
+			name:        "case 2",
+			annotations: eni,
+			release:     "18.1.0",
+		},
+		{
+			// ENI mode on a release without direct upgrade to Cilium
+			name:        "case 3",
+			annotations: eni,
+			release:     "17.4.0",
+			err:         notAllowedError,
+		},
+		{
+			// ENI mode with Cilium pod CIDR
+			name: "case 4",
+			annotations: map[string]string{
+				annotation.CiliumIpamModeAnnotation: annotation.CiliumIpamModeENI,
+				annotation.CiliumPodCidr:            "192.168.0.0/16",
+			},
+			release: "19.0.0",
+			err:     notAllowedError,
+		},
+		{
+			// ENI mode with NetworkPool
+			name:        "case 5",
+			annotations: eni,
+			release:     "19.0.0",
+			networkPool: "np1",
+			err:         notAllowedError,
+		},
+		{
+			// ENI mode with prefix delegation
+			name:                  "case 6",
+			annotations:           eni,
+			release:               "19.0.0",
+			awsClusterAnnotations: map[string]string{annotation.AWSCNIPrefixDelegation: "true"},
+			err:                   notAllowedError,
+		},
+		{
+			// ENI mode with warm IP target
+			name:                  "case 7",
+			annotations:           eni,
+			release:               "19.0.0",
+			awsClusterAnnotations: map[string]string{annotation.AWSCNIWarmIPTarget: "5"},
+			err:                   notAllowedError,
+		},
+		{
+			// ENI mode before the AWSCluster exists
+			name:         "case 8",
+			annotations:  eni,
+			release:      "19.0.0",
+			noAWSCluster: true,
+		},
+		{
+			// Unrelated update of a cluster already in ENI mode
+			name:           "case 9",
+			oldAnnotations: eni,
+			annotations:    eni,
+			release:        "19.0.0",
+			networkPool:    "np1",
+			update:         true,
+		},
+		{
+			// Enabling ENI mode on update
+			name:        "case 10",
+			annotations: eni,
+			release:     "19.0.0",
+			networkPool: "np1",
+			update:      true,
+			err:         notAllowedError,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			v := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+			}
+
+			if !tc.noAWSCluster {
+				awsCluster := unittest.DefaultAWSCluster()
+				awsCluster.Spec.Provider.Nodes.NetworkPool = tc.networkPool
+				awsCluster.SetAnnotations(tc.awsClusterAnnotations)
+				err := fakeK8sClient.CtrlClient().Create(context.Background(), awsCluster)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cluster := unittest.DefaultCluster()
+			cluster.Annotations = tc.annotations
+			cluster.Labels[label.ReleaseVersion] = tc.release
+
+			var oldCluster *capi.Cluster
+			if tc.update {
+				oldCluster = unittest.DefaultCluster()
+				oldCluster.Annotations = tc.oldAnnotations
+				oldCluster.Labels[label.ReleaseVersion] = tc.release
+			}
+
+			err := v.ValidateCiliumEniMode(cluster, oldCluster)
+			if microerror.Cause(err) != tc.err {
+				t.Fatalf("Expected error to be %q, got %q", microerror.Cause(tc.err), err)
+			}
+		})
+	}
+}
+
 func Test_ValidateCiliumIpamModeUnchanged(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -989,3 +1440,54 @@ func Test_ValidateCiliumIpamModeUnchanged(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateDelete(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+
+		allowed bool
+	}{
+		{
+			// No deletion protection
+			name:    "case 0",
+			allowed: true,
+		},
+		{
+			// Deletion protection enabled
+			name:        "case 1",
+			annotations: map[string]string{aws.AnnotationDeletionProtection: "true"},
+			allowed:     false,
+		},
+		{
+			// Deletion protection disabled
+			name:        "case 2",
+			annotations: map[string]string{aws.AnnotationDeletionProtection: "false"},
+			allowed:     true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			v := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+			cluster := unittest.DefaultCluster()
+			cluster.SetAnnotations(tc.annotations)
+
+			request, err := unittest.DeleteAdmissionRequest(cluster, metav1.GroupVersionKind(aws.ClusterGVK))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			allowed, err := v.Validate(&request)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if allowed != tc.allowed {
+				t.Fatalf("expected %v to not differ from %v", allowed, tc.allowed)
+			}
+		})
+	}
+}
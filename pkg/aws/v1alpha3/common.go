@@ -1,11 +1,19 @@
+// Package v1alpha3 implements the validation and defaulting shared by the
+// admitters of the v1alpha3 resources.
+//
+// Validations take the old object, which is nil on create. Validations added
+// after resources exist only check the fields they cover when these change,
+// so that existing resources are not blocked from unrelated updates.
 package v1alpha3
 
 import (
 	"strings"
 
 	"github.com/blang/semver/v4"
+	infrastructurev1alpha3 "github.com/giantswarm/apiextensions/v6/pkg/apis/infrastructure/v1alpha3"
 	"github.com/giantswarm/microerror"
 	"k8s.io/apimachinery/pkg/types"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
 )
@@ -35,14 +43,38 @@ const (
 	// FirstCiliumRelease is the first Cilium CNI GS release
 	FirstCiliumRelease = "19.0.0-alpha1"
 
+	// FirstCiliumEniModeRelease is the first GS release on which the Cilium ENI
+	// IPAM mode can be configured, ahead of the upgrade to a Cilium release
+	FirstCiliumEniModeRelease = "18.0.0"
+
 	// FirstOrgNamespaceRelease is the first GS release that creates Clusters in Org Namespaces by default
 	FirstOrgNamespaceRelease = "16.0.0"
 
+	// ArchitectureARM64 is the architecture of ARM instance types
+	ArchitectureARM64 = "arm64"
+
+	// ArchitectureX8664 is the architecture of x86 instance types
+	ArchitectureX8664 = "x86_64"
+
 	// GiantSwarmLabelPart is the part of label keys that shows that they are protected giantswarm labels
 	GiantSwarmLabelPart = "giantswarm.io"
 
 	// GiantSwarmLabelPart is the part of label keys that shows that they are protected giantswarm labels
 	ProviderTagLabelPart = "tag.provider.giantswarm.io"
+
+	// MaxProviderTagLabels is the maximum number of provider tag labels per
+	// resource. AWS allows 50 tags per resource, which leaves room for the
+	// tags added by the operators.
+	MaxProviderTagLabels = 40
+
+	// MaxProviderTagKeyLength is the maximum length of an AWS tag key
+	MaxProviderTagKeyLength = 128
+
+	// MaxProviderTagValueLength is the maximum length of an AWS tag value
+	MaxProviderTagValueLength = 256
+
+	// ReservedProviderTagPrefix is the AWS tag key prefix reserved for AWS use
+	ReservedProviderTagPrefix = "aws:"
 )
 
 const (
@@ -52,6 +84,24 @@ const (
 	AnnotationUpdatePauseTime    = "alpha.aws.giantswarm.io/update-pause-time"
 
 	AnnotationAlphaNodeTerminateUnhealthy = "alpha.node.giantswarm.io/terminate-unhealthy"
+
+	// AnnotationDeletionProtection set to "true" on a Cluster prevents its deletion.
+	AnnotationDeletionProtection = "giantswarm.io/deletion-protection"
+
+	// AnnotationUpdateScheduleTargetTimeOriginal records the scheduled upgrade
+	// time as given by the user before it was normalized to RFC822 UTC.
+	AnnotationUpdateScheduleTargetTimeOriginal = "alpha.giantswarm.io/update-schedule-target-time-original"
+)
+
+// GroupVersionKinds of the resources served by the admission webhooks.
+var (
+	AWSClusterGVK           = infrastructurev1alpha3.SchemeGroupVersion.WithKind("AWSCluster")
+	AWSControlPlaneGVK      = infrastructurev1alpha3.SchemeGroupVersion.WithKind("AWSControlPlane")
+	AWSMachineDeploymentGVK = infrastructurev1alpha3.SchemeGroupVersion.WithKind("AWSMachineDeployment")
+	ClusterGVK              = capi.GroupVersion.WithKind("Cluster")
+	G8sControlPlaneGVK      = infrastructurev1alpha3.SchemeGroupVersion.WithKind("G8sControlPlane")
+	MachineDeploymentGVK    = capi.GroupVersion.WithKind("MachineDeployment")
+	NetworkPoolGVK          = infrastructurev1alpha3.SchemeGroupVersion.WithKind("NetworkPool")
 )
 
 // DefaultCredentialSecret returns the default credentials for clusters
@@ -67,16 +117,15 @@ func ValidMasterReplicas() []int {
 	return []int{1, 3}
 }
 
-// ValidLabelAdmins returns the list of accounts used to manipulate labels
-func ValidLabelAdmins() []string {
-	return []string{
-		"system:serviceaccount:giantswarm:api",
-	}
+// ValidServicePriorities are the allowed values of the service priority label
+func ValidServicePriorities() []string {
+	return []string{"highest", "medium", "lowest"}
 }
 
-// VersionLabels are the labels which are considered version labels
+// VersionLabels are the labels which are considered version labels, i.e. the
+// release label and the operator version labels propagated with it
 func VersionLabels() []string {
-	return []string{label.Release, label.ClusterOperatorVersion}
+	return []string{label.Release, label.AWSOperatorVersion, label.ClusterOperatorVersion}
 }
 
 // IsGiantSwarmLabel returns whether a label is considered a giantswarm label
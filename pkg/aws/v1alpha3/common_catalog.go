@@ -0,0 +1,200 @@
+package v1alpha3
+
+import (
+	_ "embed"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/mutator"
+)
+
+//go:embed instance_types.yaml
+var instanceTypeCatalogData []byte
+
+// InstanceTypeCatalog describes the instance types known to the admission
+// controller. Instance types missing from the catalog are only validated
+// against the configured lists of allowed instance types.
+type InstanceTypeCatalog struct {
+	// InstanceTypes are the known instance types, by name.
+	InstanceTypes map[string]InstanceType `json:"instanceTypes"`
+}
+
+// InstanceType describes an instance type.
+type InstanceType struct {
+	// Family is the instance family, e.g. m5.
+	Family string `json:"family"`
+	// Architecture is the CPU architecture, either x86_64 or arm64.
+	Architecture string `json:"architecture"`
+	// CPU is the number of vCPUs.
+	CPU int `json:"cpu"`
+	// MemoryMiB is the memory in MiB.
+	MemoryMiB int `json:"memoryMiB"`
+}
+
+// InstanceTypePolicy configures the validation of instance types of the
+// installation against the instance type catalog.
+type InstanceTypePolicy struct {
+	// FirstARMRelease is the first release supporting ARM instance types. ARM
+	// instance types are not restricted by release when it is empty.
+	FirstARMRelease string `json:"firstARMRelease,omitempty"`
+	// MinMasterCPU is the minimum number of vCPUs of master instance types.
+	MinMasterCPU int `json:"minMasterCPU,omitempty"`
+	// MinMasterMemoryMiB is the minimum memory of master instance types in
+	// MiB.
+	MinMasterMemoryMiB int `json:"minMasterMemoryMiB,omitempty"`
+	// UnavailableAvailabilityZones lists the availability zones in which an
+	// instance type is not offered, by instance type. Availability zone names
+	// map to different zones in each AWS account, so they are configured per
+	// installation.
+	UnavailableAvailabilityZones map[string][]string `json:"unavailableAvailabilityZones,omitempty"`
+}
+
+// LoadInstanceTypeCatalog returns the instance type catalog bundled with the
+// admission controller.
+func LoadInstanceTypeCatalog() (InstanceTypeCatalog, error) {
+	return ParseInstanceTypeCatalog(instanceTypeCatalogData)
+}
+
+// ParseInstanceTypeCatalog reads an InstanceTypeCatalog from YAML or JSON.
+func ParseInstanceTypeCatalog(data []byte) (InstanceTypeCatalog, error) {
+	var catalog InstanceTypeCatalog
+	err := yaml.UnmarshalStrict(data, &catalog)
+	if err != nil {
+		return InstanceTypeCatalog{}, microerror.Maskf(invalidConfigError, "unable to parse instance type catalog: %v", err)
+	}
+	err = catalog.Validate()
+	if err != nil {
+		return InstanceTypeCatalog{}, microerror.Maskf(invalidConfigError, "instance type catalog is invalid: %v", err)
+	}
+
+	return catalog, nil
+}
+
+// Validate returns an error if an instance type lacks metadata or has an
+// unknown architecture.
+func (c InstanceTypeCatalog) Validate() error {
+	for name, instanceType := range c.InstanceTypes {
+		if instanceType.Family == "" || instanceType.CPU <= 0 || instanceType.MemoryMiB <= 0 {
+			return microerror.Maskf(parsingFailedError, "instance type %s must have a family, cpu and memoryMiB", name)
+		}
+		if instanceType.Architecture != ArchitectureX8664 && instanceType.Architecture != ArchitectureARM64 {
+			return microerror.Maskf(parsingFailedError, "instance type %s has unknown architecture %#q", name, instanceType.Architecture)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if the first ARM release is not a valid version
+// or a minimum is negative.
+func (p InstanceTypePolicy) Validate() error {
+	if p.FirstARMRelease != "" {
+		if _, err := semver.Parse(releaseVersionString(p.FirstARMRelease)); err != nil {
+			return microerror.Maskf(parsingFailedError, "first ARM release %q is not a valid version: %v", p.FirstARMRelease, err)
+		}
+	}
+	if p.MinMasterCPU < 0 || p.MinMasterMemoryMiB < 0 {
+		return microerror.Maskf(parsingFailedError, "minimum master size must not be negative")
+	}
+	return nil
+}
+
+// ARMSupported returns whether the release supports ARM instance types.
+func (p InstanceTypePolicy) ARMSupported(releaseVersion *semver.Version) bool {
+	if p.FirstARMRelease == "" {
+		return true
+	}
+	armVersion, _ := semver.Parse(releaseVersionString(p.FirstARMRelease))
+	return releaseVersion.GE(armVersion)
+}
+
+// Lookup returns the instance type of the given name and whether it is known.
+func (c InstanceTypeCatalog) Lookup(name string) (InstanceType, bool) {
+	instanceType, ok := c.InstanceTypes[name]
+	return instanceType, ok
+}
+
+// AlikeInstanceTypes returns the instance types with the same architecture,
+// vCPUs and memory as the given one, starting with itself and followed by the
+// others in alphabetical order. An unknown instance type is only alike to
+// itself.
+func (c InstanceTypeCatalog) AlikeInstanceTypes(name string) []string {
+	t, ok := c.Lookup(name)
+	if !ok {
+		return []string{name}
+	}
+	var alike []string
+	for other, o := range c.InstanceTypes {
+		if other != name && o.Architecture == t.Architecture && o.CPU == t.CPU && o.MemoryMiB == t.MemoryMiB {
+			alike = append(alike, other)
+		}
+	}
+	sort.Strings(alike)
+	return append([]string{name}, alike...)
+}
+
+// ValidateInstanceTypeSupported validates that a known instance type is
+// offered in all of the given availability zones, and that ARM instance
+// types are only used with releases which support them.
+func ValidateInstanceTypeSupported(catalog InstanceTypeCatalog, policy InstanceTypePolicy, kind string, meta metav1.Object, instanceType string, availabilityZones []string) error {
+	t, ok := catalog.Lookup(instanceType)
+	if !ok {
+		return nil
+	}
+
+	var unavailable []string
+	for _, az := range availabilityZones {
+		if Contains(policy.UnavailableAvailabilityZones[instanceType], az) && !Contains(unavailable, az) {
+			unavailable = append(unavailable, az)
+		}
+	}
+	if len(unavailable) > 0 {
+		return microerror.Maskf(notAllowedError, "Instance type %s of %s %s/%s is not available in availability zones %s.",
+			instanceType,
+			kind,
+			meta.GetNamespace(),
+			meta.GetName(),
+			strings.Join(unavailable, ", "))
+	}
+
+	if t.Architecture == ArchitectureARM64 {
+		releaseVersion, err := ReleaseVersion(meta, []mutator.PatchOperation{})
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if !policy.ARMSupported(releaseVersion) {
+			return microerror.Maskf(notAllowedError, "Instance type %s of %s %s/%s has architecture %s, which is only supported from release %s on.",
+				instanceType,
+				kind,
+				meta.GetNamespace(),
+				meta.GetName(),
+				t.Architecture,
+				policy.FirstARMRelease)
+		}
+	}
+
+	return nil
+}
+
+// ValidateMasterInstanceSize validates that a known master instance type has
+// at least the minimum vCPUs and memory of the policy.
+func ValidateMasterInstanceSize(catalog InstanceTypeCatalog, policy InstanceTypePolicy, kind string, meta metav1.Object, instanceType string) error {
+	t, ok := catalog.Lookup(instanceType)
+	if !ok {
+		return nil
+	}
+	if t.CPU < policy.MinMasterCPU || t.MemoryMiB < policy.MinMasterMemoryMiB {
+		return microerror.Maskf(notAllowedError, "Master instance type %s of %s %s/%s is too small. Master instance types need at least %d vCPUs and %d MiB memory.",
+			instanceType,
+			kind,
+			meta.GetNamespace(),
+			meta.GetName(),
+			policy.MinMasterCPU,
+			policy.MinMasterMemoryMiB)
+	}
+	return nil
+}
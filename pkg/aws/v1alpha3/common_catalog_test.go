@@ -0,0 +1,270 @@
+package v1alpha3
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
+	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
+)
+
+func testInstanceTypeCatalog() InstanceTypeCatalog {
+	return InstanceTypeCatalog{
+		InstanceTypes: map[string]InstanceType{
+			"m5.large":   {Family: "m5", Architecture: ArchitectureX8664, CPU: 2, MemoryMiB: 8192},
+			"m5.xlarge":  {Family: "m5", Architecture: ArchitectureX8664, CPU: 4, MemoryMiB: 16384},
+			"m5a.xlarge": {Family: "m5a", Architecture: ArchitectureX8664, CPU: 4, MemoryMiB: 16384},
+			"m6i.xlarge": {Family: "m6i", Architecture: ArchitectureX8664, CPU: 4, MemoryMiB: 16384},
+			"m6g.xlarge": {Family: "m6g", Architecture: ArchitectureARM64, CPU: 4, MemoryMiB: 16384},
+		},
+	}
+}
+
+func testInstanceTypePolicy() InstanceTypePolicy {
+	return InstanceTypePolicy{
+		FirstARMRelease:    "20.0.0",
+		MinMasterCPU:       4,
+		MinMasterMemoryMiB: 8192,
+		UnavailableAvailabilityZones: map[string][]string{
+			"m6i.xlarge": {"eu-central-1c"},
+		},
+	}
+}
+
+func TestLoadInstanceTypeCatalog(t *testing.T) {
+	catalog, err := LoadInstanceTypeCatalog()
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// The default master instance type must be known, so that it is
+	// validated against the catalog.
+	if _, ok := catalog.Lookup(DefaultMasterInstanceType); !ok {
+		t.Fatalf("expected instance type %s to be in the catalog", DefaultMasterInstanceType)
+	}
+}
+
+func TestAlikeInstanceTypes(t *testing.T) {
+	testCases := []struct {
+		name         string
+		instanceType string
+
+		expected []string
+	}{
+		{
+			// Instance types of the same architecture, vCPUs and memory
+			name:         "case 0",
+			instanceType: "m5.xlarge",
+			expected:     []string{"m5.xlarge", "m5a.xlarge", "m6i.xlarge"},
+		},
+		{
+			// Instance type without alike instance types
+			name:         "case 1",
+			instanceType: "m6g.xlarge",
+			expected:     []string{"m6g.xlarge"},
+		},
+		{
+			// Unknown instance type
+			name:         "case 2",
+			instanceType: "x1.xlarge",
+			expected:     []string{"x1.xlarge"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			alike := testInstanceTypeCatalog().AlikeInstanceTypes(tc.instanceType)
+			if strings.Join(alike, ",") != strings.Join(tc.expected, ",") {
+				t.Fatalf("expected %v but got %v", tc.expected, alike)
+			}
+		})
+	}
+}
+
+func TestParseInstanceTypeCatalog(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+
+		valid bool
+	}{
+		{
+			// Valid catalog
+			name: "case 0",
+			content: `instanceTypes:
+  m5.xlarge:
+    family: m5
+    architecture: x86_64
+    cpu: 4
+    memoryMiB: 16384
+`,
+			valid: true,
+		},
+		{
+			// Unknown architecture
+			name:    "case 1",
+			content: "instanceTypes:\n  m5.xlarge:\n    family: m5\n    architecture: i386\n    cpu: 4\n    memoryMiB: 16384\n",
+			valid:   false,
+		},
+		{
+			// Missing memory
+			name:    "case 2",
+			content: "instanceTypes:\n  m5.xlarge:\n    family: m5\n    architecture: x86_64\n    cpu: 4\n",
+			valid:   false,
+		},
+		{
+			// Unknown field
+			name:    "case 3",
+			content: "instanceTypes:\n  m5.xlarge:\n    family: m5\n    architecture: x86_64\n    cpu: 4\n    memoryMiB: 16384\n    gpu: 1\n",
+			valid:   false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := ParseInstanceTypeCatalog([]byte(tc.content))
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error but got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateInstanceTypeSupported(t *testing.T) {
+	testCases := []struct {
+		name              string
+		instanceType      string
+		availabilityZones []string
+		release           string
+		unconfigured      bool
+
+		allowed bool
+	}{
+		{
+			// Available instance type
+			name:              "case 0",
+			instanceType:      "m6i.xlarge",
+			availabilityZones: []string{"eu-central-1a", "eu-central-1b"},
+			release:           "16.0.0",
+			allowed:           true,
+		},
+		{
+			// Instance type unavailable in an availability zone
+			name:              "case 1",
+			instanceType:      "m6i.xlarge",
+			availabilityZones: []string{"eu-central-1a", "eu-central-1c"},
+			release:           "16.0.0",
+			allowed:           false,
+		},
+		{
+			// Unknown instance type is not validated
+			name:              "case 2",
+			instanceType:      "x1.xlarge",
+			availabilityZones: []string{"eu-central-1c"},
+			release:           "16.0.0",
+			allowed:           true,
+		},
+		{
+			// ARM instance type on a release without ARM support
+			name:              "case 3",
+			instanceType:      "m6g.xlarge",
+			availabilityZones: []string{"eu-central-1a"},
+			release:           "16.0.0",
+			allowed:           false,
+		},
+		{
+			// ARM instance type on a release with ARM support
+			name:              "case 4",
+			instanceType:      "m6g.xlarge",
+			availabilityZones: []string{"eu-central-1a"},
+			release:           "20.0.0",
+			allowed:           true,
+		},
+		{
+			// Instance type and ARM release are not configured
+			name:              "case 5",
+			instanceType:      "m6g.xlarge",
+			availabilityZones: []string{"eu-central-1c"},
+			release:           "16.0.0",
+			unconfigured:      true,
+			allowed:           true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			md := unittest.DefaultAWSMachineDeployment()
+			md.Labels[label.Release] = tc.release
+			policy := testInstanceTypePolicy()
+			if tc.unconfigured {
+				policy = InstanceTypePolicy{}
+			}
+
+			err := ValidateInstanceTypeSupported(testInstanceTypeCatalog(), policy, "AWSMachineDeployment", md, tc.instanceType, tc.availabilityZones)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && !IsNotAllowed(err) {
+				t.Fatalf("expected not allowed error but got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateMasterInstanceSize(t *testing.T) {
+	testCases := []struct {
+		name         string
+		instanceType string
+		unconfigured bool
+
+		allowed bool
+	}{
+		{
+			// Large enough instance type
+			name:         "case 0",
+			instanceType: "m5.xlarge",
+			allowed:      true,
+		},
+		{
+			// Too small instance type
+			name:         "case 1",
+			instanceType: "m5.large",
+			allowed:      false,
+		},
+		{
+			// Unknown instance type is not validated
+			name:         "case 2",
+			instanceType: "x1.large",
+			allowed:      true,
+		},
+		{
+			// Minimum master size is not configured
+			name:         "case 3",
+			instanceType: "m5.large",
+			unconfigured: true,
+			allowed:      true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			controlPlane := unittest.DefaultAWSControlPlane()
+			policy := testInstanceTypePolicy()
+			if tc.unconfigured {
+				policy = InstanceTypePolicy{}
+			}
+
+			err := ValidateMasterInstanceSize(testInstanceTypeCatalog(), policy, "AWSControlPlane", &controlPlane, tc.instanceType)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && !IsNotAllowed(err) {
+				t.Fatalf("expected not allowed error but got %v", err)
+			}
+		})
+	}
+}
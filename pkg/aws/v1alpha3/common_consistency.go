@@ -0,0 +1,79 @@
+package v1alpha3
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/key"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
+)
+
+// ConsistentLabels must agree between all CRs of a cluster.
+func ConsistentLabels() []string {
+	return []string{label.Cluster, label.Organization}
+}
+
+// ClusterLeadLabels must agree with the Cluster CR, which leads upgrades of
+// the other CRs of the cluster.
+func ClusterLeadLabels() []string {
+	return []string{label.Release, label.ClusterOperatorVersion, label.AWSOperatorVersion}
+}
+
+// ValidateLabelConsistency returns a notAllowedError naming every CR of the
+// cluster which disagrees with the labels changed from oldObj to obj.
+func ValidateLabelConsistency(m *Handler, kind string, oldObj client.Object, obj client.Object) error {
+	clusterID := key.Cluster(oldObj)
+	if clusterID == "" {
+		clusterID = key.Cluster(obj)
+	}
+	if clusterID == "" {
+		return nil
+	}
+
+	var changed []string
+	for _, l := range append(ConsistentLabels(), ClusterLeadLabels()...) {
+		if obj.GetLabels()[l] != "" && oldObj.GetLabels()[l] != obj.GetLabels()[l] {
+			changed = append(changed, l)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	siblings, err := FetchClusterObjects(m, oldObj.GetNamespace(), clusterID)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var mismatches []string
+	for _, sibling := range siblings {
+		if sibling.Kind == kind && sibling.GetName() == obj.GetName() {
+			continue
+		}
+		for _, l := range changed {
+			// The Cluster leads upgrades, so its children lag behind it.
+			if Contains(ClusterLeadLabels(), l) && (kind == "Cluster" || sibling.Kind != "Cluster") {
+				continue
+			}
+			value := sibling.GetLabels()[l]
+			if value == "" || value == obj.GetLabels()[l] {
+				continue
+			}
+			mismatches = append(mismatches, fmt.Sprintf("%s %s has %s=%s", sibling.Kind, sibling.GetName(), l, value))
+		}
+	}
+	if len(mismatches) == 0 {
+		return nil
+	}
+
+	return microerror.Maskf(notAllowedError, "Labels of %s %s/%s do not match the other resources of cluster %s: %s.",
+		kind,
+		obj.GetNamespace(),
+		obj.GetName(),
+		clusterID,
+		strings.Join(mismatches, ", "),
+	)
+}
@@ -0,0 +1,132 @@
+package v1alpha3
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
+	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
+)
+
+func TestValidateLabelConsistency(t *testing.T) {
+	testCases := []struct {
+		name                string
+		kind                string
+		clusterRelease      string
+		siblingOrganization string
+		labels              map[string]string
+
+		allowed bool
+		message string
+	}{
+		{
+			// MachineDeployment follows the release of the Cluster while the
+			// AWSMachineDeployment still lags behind
+			name:           "case 0",
+			kind:           "MachineDeployment",
+			clusterRelease: "100.1.0",
+			labels:         map[string]string{label.Release: "100.1.0"},
+			allowed:        true,
+		},
+		{
+			// MachineDeployment release differs from the Cluster
+			name:           "case 1",
+			kind:           "MachineDeployment",
+			clusterRelease: "100.1.0",
+			labels:         map[string]string{label.Release: "100.2.0"},
+			allowed:        false,
+			message:        "Cluster " + unittest.DefaultClusterID + " has release.giantswarm.io/version=100.1.0.",
+		},
+		{
+			// Cluster release change leads the other resources
+			name:           "case 2",
+			kind:           "Cluster",
+			clusterRelease: "100.0.0",
+			labels:         map[string]string{label.Release: "100.1.0"},
+			allowed:        true,
+		},
+		{
+			// MachineDeployment organization differs from all other resources
+			name:    "case 3",
+			kind:    "MachineDeployment",
+			labels:  map[string]string{label.Organization: "other"},
+			allowed: false,
+			message: "AWSMachineDeployment " + unittest.DefaultMachineDeploymentID + " has giantswarm.io/organization=example-organization",
+		},
+		{
+			// MachineDeployment moved to another cluster
+			name:    "case 4",
+			kind:    "MachineDeployment",
+			labels:  map[string]string{label.Cluster: "other"},
+			allowed: false,
+			message: "Cluster " + unittest.DefaultClusterID + " has giantswarm.io/cluster=" + unittest.DefaultClusterID,
+		},
+		{
+			// Unrelated label change with existing drift of the organization
+			name:                "case 5",
+			kind:                "MachineDeployment",
+			siblingOrganization: "drifted",
+			labels:              map[string]string{"example-key": "changed"},
+			allowed:             true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			handler := &Handler{
+				K8sClient: fakeK8sClient,
+				Logger:    microloggertest.New(),
+			}
+
+			clusterRelease := tc.clusterRelease
+			if clusterRelease == "" {
+				clusterRelease = "100.0.0"
+			}
+			siblingOrganization := tc.siblingOrganization
+			if siblingOrganization == "" {
+				siblingOrganization = "example-organization"
+			}
+
+			cluster := unittest.DefaultCluster()
+			cluster.Labels[label.Release] = clusterRelease
+			awsCluster := unittest.DefaultAWSCluster()
+			machineDeployment := unittest.DefaultMachineDeployment()
+			machineDeployment.Labels[label.Organization] = "example-organization"
+			awsMachineDeployment := unittest.DefaultAWSMachineDeployment()
+			awsMachineDeployment.Labels[label.Organization] = siblingOrganization
+			for _, obj := range []client.Object{cluster, awsCluster, machineDeployment, awsMachineDeployment} {
+				err := fakeK8sClient.CtrlClient().Create(context.Background(), obj)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var oldObj, obj client.Object = machineDeployment, machineDeployment.DeepCopy()
+			if tc.kind == "Cluster" {
+				oldObj, obj = cluster, cluster.DeepCopy()
+			}
+			for k, v := range tc.labels {
+				obj.GetLabels()[k] = v
+			}
+
+			err := ValidateLabelConsistency(handler, tc.kind, oldObj, obj)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed {
+				if !IsNotAllowed(err) {
+					t.Fatalf("expected not allowed error but got %v", err)
+				}
+				if !strings.Contains(err.Error(), tc.message) {
+					t.Fatalf("expected error to contain %q but got %v", tc.message, err)
+				}
+			}
+		})
+	}
+}
@@ -11,7 +11,9 @@ import (
 	infrastructurev1alpha3 "github.com/giantswarm/apiextensions/v6/pkg/apis/infrastructure/v1alpha3"
 	"github.com/giantswarm/backoff"
 	"github.com/giantswarm/microerror"
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
 	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -105,6 +107,27 @@ func FetchAWSControlPlane(m *Handler, meta metav1.Object) (*infrastructurev1alph
 	return &awsControlPlane, nil
 }
 
+// FetchAWSClustersUsingNetworkPool returns the AWSClusters which are not being
+// deleted and reference the NetworkPool in Spec.Provider.Nodes.NetworkPool.
+// The reference is a name only, so AWSClusters in all namespaces, e.g. org
+// namespaces, are considered.
+func FetchAWSClustersUsingNetworkPool(m *Handler, networkPool *infrastructurev1alpha3.NetworkPool) ([]infrastructurev1alpha3.AWSCluster, error) {
+	awsClusters := infrastructurev1alpha3.AWSClusterList{}
+	err := m.K8sClient.CtrlClient().List(context.Background(), &awsClusters)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var users []infrastructurev1alpha3.AWSCluster
+	for _, awsCluster := range awsClusters.Items {
+		if awsCluster.Spec.Provider.Nodes.NetworkPool != networkPool.GetName() || awsCluster.GetDeletionTimestamp() != nil {
+			continue
+		}
+		users = append(users, awsCluster)
+	}
+	return users, nil
+}
+
 func FetchCluster(m *Handler, meta metav1.Object) (*capi.Cluster, error) {
 	var cluster capi.Cluster
 	var err error
@@ -125,8 +148,10 @@ func FetchCluster(m *Handler, meta metav1.Object) (*capi.Cluster, error) {
 		m.Logger.Log("level", "debug", "message", fmt.Sprintf("Fetching Cluster %s in namespace %s.", clusterID, namespace))
 		fetch = func() error {
 			err := m.K8sClient.CtrlClient().Get(context.Background(), client.ObjectKey{Name: clusterID, Namespace: namespace}, &cluster)
-			if err != nil {
-				return microerror.Maskf(notFoundError, "An error occured looking for Cluster named %s in namespace %s: %v", clusterID, namespace, err)
+			if apierrors.IsNotFound(err) {
+				return microerror.Maskf(notFoundError, "Looking for Cluster named %s in namespace %s but it was not found.", clusterID, namespace)
+			} else if err != nil {
+				return microerror.Mask(err)
 			}
 			return nil
 		}
@@ -268,3 +293,101 @@ func FetchRelease(m *Handler, version *semver.Version) (*releasev1alpha1.Release
 	}
 	return &release, nil
 }
+
+// ClusterObject is a CR of one of the kinds making up a cluster.
+type ClusterObject struct {
+	Kind string
+	client.Object
+}
+
+// FetchClusterObjects returns the Cluster, AWSCluster, G8sControlPlane,
+// AWSControlPlane, MachineDeployment and AWSMachineDeployment CRs in the
+// namespace which are labelled with the cluster ID.
+func FetchClusterObjects(m *Handler, namespace string, clusterID string) ([]ClusterObject, error) {
+	ctx := context.Background()
+	opts := []client.ListOption{
+		client.InNamespace(namespace),
+		client.MatchingLabels{label.Cluster: clusterID},
+	}
+
+	var objects []ClusterObject
+	{
+		clusters := capi.ClusterList{}
+		err := m.K8sClient.CtrlClient().List(ctx, &clusters, opts...)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		for i := range clusters.Items {
+			objects = append(objects, ClusterObject{Kind: "Cluster", Object: &clusters.Items[i]})
+		}
+	}
+	{
+		awsClusters := infrastructurev1alpha3.AWSClusterList{}
+		err := m.K8sClient.CtrlClient().List(ctx, &awsClusters, opts...)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		for i := range awsClusters.Items {
+			objects = append(objects, ClusterObject{Kind: "AWSCluster", Object: &awsClusters.Items[i]})
+		}
+	}
+	{
+		g8sControlPlanes := infrastructurev1alpha3.G8sControlPlaneList{}
+		err := m.K8sClient.CtrlClient().List(ctx, &g8sControlPlanes, opts...)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		for i := range g8sControlPlanes.Items {
+			objects = append(objects, ClusterObject{Kind: "G8sControlPlane", Object: &g8sControlPlanes.Items[i]})
+		}
+	}
+	{
+		awsControlPlanes := infrastructurev1alpha3.AWSControlPlaneList{}
+		err := m.K8sClient.CtrlClient().List(ctx, &awsControlPlanes, opts...)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		for i := range awsControlPlanes.Items {
+			objects = append(objects, ClusterObject{Kind: "AWSControlPlane", Object: &awsControlPlanes.Items[i]})
+		}
+	}
+	{
+		machineDeployments := capi.MachineDeploymentList{}
+		err := m.K8sClient.CtrlClient().List(ctx, &machineDeployments, opts...)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		for i := range machineDeployments.Items {
+			objects = append(objects, ClusterObject{Kind: "MachineDeployment", Object: &machineDeployments.Items[i]})
+		}
+	}
+	{
+		awsMachineDeployments := infrastructurev1alpha3.AWSMachineDeploymentList{}
+		err := m.K8sClient.CtrlClient().List(ctx, &awsMachineDeployments, opts...)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		for i := range awsMachineDeployments.Items {
+			objects = append(objects, ClusterObject{Kind: "AWSMachineDeployment", Object: &awsMachineDeployments.Items[i]})
+		}
+	}
+
+	return objects, nil
+}
+
+// FetchNamespaceOrganization returns the Organization CR whose status
+// namespace is the given namespace.
+func FetchNamespaceOrganization(m *Handler, namespace string) (*securityv1alpha1.Organization, error) {
+	var organizations securityv1alpha1.OrganizationList
+	err := m.K8sClient.CtrlClient().List(context.Background(), &organizations)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	for i := range organizations.Items {
+		if organizations.Items[i].Status.Namespace == namespace {
+			return &organizations.Items[i], nil
+		}
+	}
+
+	return nil, microerror.Maskf(notFoundError, "Looking for the Organization of namespace %s but it was not found.", namespace)
+}
@@ -0,0 +1,319 @@
+package v1alpha3
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	kustomizev1beta2 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	"github.com/giantswarm/microerror"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/key"
+)
+
+const (
+	// DefaultArgoCDNamespace is the namespace of Argo CD Applications tracked
+	// without a namespace.
+	DefaultArgoCDNamespace = "argocd"
+)
+
+var (
+	// gitopsDeletionKinds are the kinds whose deletions are validated. The
+	// webhooks of the other kinds are not subscribed to DELETE operations.
+	gitopsDeletionKinds = []string{"AWSControlPlane", "Cluster", "G8sControlPlane"}
+
+	fluxHelmReleaseGVK   = schema.GroupVersionKind{Group: "helm.toolkit.fluxcd.io", Version: "v2beta1", Kind: "HelmRelease"}
+	argoCDApplicationGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Application"}
+)
+
+// GitopsGuard requires the GitOps owner of an object to be suspended before
+// certain changes, so that the owner does not revert or repeat them.
+type GitopsGuard struct {
+	// ArgoCDNamespace defaults to DefaultArgoCDNamespace.
+	ArgoCDNamespace string       `json:"argoCDNamespace,omitempty"`
+	Rules           []GitopsRule `json:"rules"`
+}
+
+// GitopsRule describes changes of objects which require the GitOps owner to
+// be suspended.
+type GitopsRule struct {
+	// Kinds the rule applies to. An empty list applies to all kinds.
+	Kinds []string `json:"kinds,omitempty"`
+	// MajorUpgrade matches changes of the major version of the release label.
+	MajorUpgrade bool `json:"majorUpgrade,omitempty"`
+	// UpgradeTo matches changes of the release label from a version before
+	// the given version to the given version or later.
+	UpgradeTo string `json:"upgradeTo,omitempty"`
+	// Deletion matches deletions. It requires Kinds to only list kinds whose
+	// deletions are validated.
+	Deletion bool `json:"deletion,omitempty"`
+	// Fields matches changes of the given fields. Paths are separated by dots,
+	// keys containing dots are quoted in brackets, e.g.
+	// `metadata.annotations["cilium.giantswarm.io/ipam-mode"]`.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// DefaultGitopsGuard requires the owner to be suspended for upgrades of
+// clusters to the first Cilium release.
+func DefaultGitopsGuard() GitopsGuard {
+	return GitopsGuard{
+		Rules: []GitopsRule{
+			{Kinds: []string{"Cluster"}, UpgradeTo: FirstCiliumRelease},
+		},
+	}
+}
+
+// Validate returns an error if a rule matches no changes, matches deletions
+// which are not validated or has an invalid version or field path.
+func (g GitopsGuard) Validate() error {
+	for i, rule := range g.Rules {
+		if !rule.MajorUpgrade && rule.UpgradeTo == "" && !rule.Deletion && len(rule.Fields) == 0 {
+			return microerror.Maskf(parsingFailedError, "rule %d matches no changes", i)
+		}
+		if rule.UpgradeTo != "" {
+			if _, err := semver.New(rule.UpgradeTo); err != nil {
+				return microerror.Maskf(parsingFailedError, "rule %d: upgradeTo %q is invalid: %v", i, rule.UpgradeTo, err)
+			}
+		}
+		if rule.Deletion {
+			if len(rule.Kinds) == 0 {
+				return microerror.Maskf(parsingFailedError, "rule %d matches deletions but lists no kinds, deletions are only validated for %s", i, strings.Join(gitopsDeletionKinds, ", "))
+			}
+			for _, kind := range rule.Kinds {
+				if !Contains(gitopsDeletionKinds, kind) {
+					return microerror.Maskf(parsingFailedError, "rule %d matches deletions of %s, deletions are only validated for %s", i, kind, strings.Join(gitopsDeletionKinds, ", "))
+				}
+			}
+		}
+		for _, field := range rule.Fields {
+			if _, err := parseFieldPath(field); err != nil {
+				return microerror.Maskf(parsingFailedError, "rule %d: %v", i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateGitopsPaused returns a notAllowedError if a rule of the guard
+// matches the change from oldObj to newObj and a GitOps owner of the object
+// is not suspended. newObj is nil for deletions.
+func ValidateGitopsPaused(m *Handler, guard GitopsGuard, kind string, oldObj client.Object, newObj client.Object) error {
+	violation, err := guard.Violation(m, kind, oldObj, newObj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if violation != "" {
+		return microerror.Maskf(notAllowedError, "%s", violation)
+	}
+	return nil
+}
+
+// Violation describes why the change from oldObj to newObj is not allowed, or
+// returns an empty string if it is. Owners which can not be found are
+// ignored, since they might run outside of the management cluster.
+func (g GitopsGuard) Violation(m *Handler, kind string, oldObj client.Object, newObj client.Object) (string, error) {
+	changes, err := g.matchingChanges(kind, oldObj, newObj)
+	if err != nil {
+		return "", microerror.Mask(err)
+	}
+	if len(changes) == 0 {
+		return "", nil
+	}
+
+	obj := newObj
+	if obj == nil {
+		obj = oldObj
+	}
+
+	argoCDNamespace := g.ArgoCDNamespace
+	if argoCDNamespace == "" {
+		argoCDNamespace = DefaultArgoCDNamespace
+	}
+
+	var owners []string
+	if ok := key.FluxKustomizationObjectKey(obj); ok != nil {
+		kust := kustomizev1beta2.Kustomization{}
+		err = m.K8sClient.CtrlClient().Get(context.Background(), *ok, &kust)
+		if err == nil && !kust.Spec.Suspend {
+			owners = append(owners, fmt.Sprintf("Kustomization %s/%s is not suspended", ok.Namespace, ok.Name))
+		} else if err != nil && !apierrors.IsNotFound(err) {
+			return "", microerror.Mask(err)
+		}
+	}
+	if ok := key.FluxHelmReleaseObjectKey(obj); ok != nil {
+		helmRelease, found, err := fetchUnstructured(m, fluxHelmReleaseGVK, *ok)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+		if found {
+			suspended, _, _ := unstructured.NestedBool(helmRelease.Object, "spec", "suspend")
+			if !suspended {
+				owners = append(owners, fmt.Sprintf("HelmRelease %s/%s is not suspended", ok.Namespace, ok.Name))
+			}
+		}
+	}
+	if ok := key.ArgoCDApplicationObjectKey(obj, argoCDNamespace); ok != nil {
+		application, found, err := fetchUnstructured(m, argoCDApplicationGVK, *ok)
+		if err != nil {
+			return "", microerror.Mask(err)
+		}
+		if found {
+			// Argo CD can not suspend Applications, disabling the automated sync
+			// has the same effect.
+			_, automated, _ := unstructured.NestedFieldNoCopy(application.Object, "spec", "syncPolicy", "automated")
+			if automated {
+				owners = append(owners, fmt.Sprintf("Application %s/%s has automated sync enabled", ok.Namespace, ok.Name))
+			}
+		}
+	}
+
+	if len(owners) == 0 {
+		return "", nil
+	}
+
+	return fmt.Sprintf("%s %s/%s is managed by gitops but %s. Suspend it before the %s.",
+		kind,
+		obj.GetNamespace(),
+		obj.GetName(),
+		strings.Join(owners, " and "),
+		strings.Join(changes, ", "),
+	), nil
+}
+
+// matchingChanges returns a description of each change matched by the rules.
+func (g GitopsGuard) matchingChanges(kind string, oldObj client.Object, newObj client.Object) ([]string, error) {
+	var oldContent, newContent map[string]interface{}
+	var changes []string
+	for _, rule := range g.Rules {
+		if len(rule.Kinds) > 0 && !Contains(rule.Kinds, kind) {
+			continue
+		}
+		if newObj == nil {
+			if rule.Deletion {
+				changes = appendUnique(changes, "deletion")
+			}
+			continue
+		}
+		if rule.MajorUpgrade && isMajorUpgrade(oldObj, newObj) {
+			changes = appendUnique(changes, "major upgrade")
+		}
+		if rule.UpgradeTo != "" && isUpgradeTo(oldObj, newObj, rule.UpgradeTo) {
+			changes = appendUnique(changes, fmt.Sprintf("upgrade to %s", rule.UpgradeTo))
+		}
+		if len(rule.Fields) == 0 {
+			continue
+		}
+		if oldContent == nil {
+			var err error
+			oldContent, err = runtime.DefaultUnstructuredConverter.ToUnstructured(oldObj)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+			newContent, err = runtime.DefaultUnstructuredConverter.ToUnstructured(newObj)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+		}
+		for _, field := range rule.Fields {
+			path, err := parseFieldPath(field)
+			if err != nil {
+				return nil, microerror.Mask(err)
+			}
+			oldValue, _, _ := unstructured.NestedFieldNoCopy(oldContent, path...)
+			newValue, _, _ := unstructured.NestedFieldNoCopy(newContent, path...)
+			if !reflect.DeepEqual(oldValue, newValue) {
+				changes = appendUnique(changes, fmt.Sprintf("change of %s", field))
+			}
+		}
+	}
+	return changes, nil
+}
+
+func isMajorUpgrade(oldObj client.Object, newObj client.Object) bool {
+	oldRelease, err := semver.New(key.Release(oldObj))
+	if err != nil {
+		return false
+	}
+	newRelease, err := semver.New(key.Release(newObj))
+	if err != nil {
+		return false
+	}
+	return newRelease.Major > oldRelease.Major
+}
+
+func isUpgradeTo(oldObj client.Object, newObj client.Object, version string) bool {
+	boundary, err := semver.New(version)
+	if err != nil {
+		return false
+	}
+	oldRelease, err := semver.New(key.Release(oldObj))
+	if err != nil {
+		return false
+	}
+	newRelease, err := semver.New(key.Release(newObj))
+	if err != nil {
+		return false
+	}
+	return oldRelease.LT(*boundary) && newRelease.GE(*boundary)
+}
+
+// parseFieldPath splits a field path like
+// `metadata.labels["release.giantswarm.io/version"]` into its segments.
+func parseFieldPath(field string) ([]string, error) {
+	var path []string
+	rest := field
+	for rest != "" {
+		if strings.HasPrefix(rest, "[\"") {
+			end := strings.Index(rest, "\"]")
+			if end < 0 {
+				return nil, microerror.Maskf(parsingFailedError, "field path %q has an unterminated key", field)
+			}
+			path = append(path, rest[2:end])
+			rest = strings.TrimPrefix(rest[end+2:], ".")
+			continue
+		}
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		if end == 0 {
+			return nil, microerror.Maskf(parsingFailedError, "field path %q has an empty segment", field)
+		}
+		path = append(path, rest[:end])
+		rest = rest[end:]
+		rest = strings.TrimPrefix(rest, ".")
+	}
+	if len(path) == 0 {
+		return nil, microerror.Maskf(parsingFailedError, "field path must not be empty")
+	}
+	return path, nil
+}
+
+// fetchUnstructured returns the object of a kind which is not part of the
+// scheme. Missing objects and kinds are reported as not found.
+func fetchUnstructured(m *Handler, gvk schema.GroupVersionKind, objectKey client.ObjectKey) (*unstructured.Unstructured, bool, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	err := m.K8sClient.CtrlClient().Get(context.Background(), objectKey, obj)
+	if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, microerror.Mask(err)
+	}
+	return obj, true, nil
+}
+
+func appendUnique(list []string, s string) []string {
+	if Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
@@ -0,0 +1,266 @@
+package v1alpha3
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	kustomizev1beta2 "github.com/fluxcd/kustomize-controller/api/v1beta2"
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
+	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
+)
+
+func TestValidateGitopsPaused(t *testing.T) {
+	guard := GitopsGuard{
+		Rules: []GitopsRule{
+			{Kinds: []string{"Cluster"}, MajorUpgrade: true, Deletion: true},
+			{Kinds: []string{"Cluster"}, Fields: []string{`metadata.annotations["cilium.giantswarm.io/ipam-mode"]`}},
+			{Kinds: []string{"Cluster"}, UpgradeTo: "100.2.0"},
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		release     string
+		deletion    bool
+		owners      []client.Object
+
+		allowed bool
+		message string
+	}{
+		{
+			// Minor upgrade of a gitops-managed cluster
+			name:    "case 0",
+			labels:  map[string]string{"kustomize.toolkit.fluxcd.io/name": "kust1", "kustomize.toolkit.fluxcd.io/namespace": "default"},
+			release: "100.1.0",
+			owners:  []client.Object{kustomization("kust1", false)},
+			allowed: true,
+		},
+		{
+			// Major upgrade with a Kustomization which is not suspended
+			name:    "case 1",
+			labels:  map[string]string{"kustomize.toolkit.fluxcd.io/name": "kust1", "kustomize.toolkit.fluxcd.io/namespace": "default"},
+			release: "101.0.0",
+			owners:  []client.Object{kustomization("kust1", false)},
+			allowed: false,
+			message: "Kustomization default/kust1 is not suspended. Suspend it before the major upgrade",
+		},
+		{
+			// Deletion with a HelmRelease which is not suspended
+			name:     "case 2",
+			labels:   map[string]string{"helm.toolkit.fluxcd.io/name": "hr1", "helm.toolkit.fluxcd.io/namespace": "flux-system"},
+			release:  "100.0.0",
+			deletion: true,
+			owners:   []client.Object{gitopsOwner(fluxHelmReleaseGVK.GroupVersion().String(), "HelmRelease", "flux-system", "hr1", map[string]interface{}{"suspend": false})},
+			allowed:  false,
+			message:  "HelmRelease flux-system/hr1 is not suspended. Suspend it before the deletion.",
+		},
+		{
+			// Deletion with a suspended HelmRelease
+			name:     "case 3",
+			labels:   map[string]string{"helm.toolkit.fluxcd.io/name": "hr1", "helm.toolkit.fluxcd.io/namespace": "flux-system"},
+			release:  "100.0.0",
+			deletion: true,
+			owners:   []client.Object{gitopsOwner(fluxHelmReleaseGVK.GroupVersion().String(), "HelmRelease", "flux-system", "hr1", map[string]interface{}{"suspend": true})},
+			allowed:  true,
+		},
+		{
+			// Field change with an Argo CD Application which syncs automatically
+			name:        "case 4",
+			labels:      map[string]string{"argocd.argoproj.io/instance": "app1"},
+			annotations: map[string]string{"cilium.giantswarm.io/ipam-mode": "eni"},
+			release:     "100.0.0",
+			owners:      []client.Object{gitopsOwner(argoCDApplicationGVK.GroupVersion().String(), "Application", "argocd", "app1", map[string]interface{}{"syncPolicy": map[string]interface{}{"automated": map[string]interface{}{}}})},
+			allowed:     false,
+			message:     `Application argocd/app1 has automated sync enabled. Suspend it before the change of metadata.annotations["cilium.giantswarm.io/ipam-mode"].`,
+		},
+		{
+			// Field change with an Argo CD Application in another namespace
+			// without automated sync
+			name:        "case 5",
+			labels:      map[string]string{"argocd.argoproj.io/instance": "apps_app1"},
+			annotations: map[string]string{"cilium.giantswarm.io/ipam-mode": "eni"},
+			release:     "100.0.0",
+			owners:      []client.Object{gitopsOwner(argoCDApplicationGVK.GroupVersion().String(), "Application", "apps", "app1", map[string]interface{}{})},
+			allowed:     true,
+		},
+		{
+			// Major upgrade with an owner which can not be found
+			name:    "case 6",
+			labels:  map[string]string{"helm.toolkit.fluxcd.io/name": "hr1", "helm.toolkit.fluxcd.io/namespace": "flux-system"},
+			release: "101.0.0",
+			allowed: true,
+		},
+		{
+			// Major upgrade without gitops
+			name:    "case 7",
+			release: "101.0.0",
+			allowed: true,
+		},
+		{
+			// Upgrade to a guarded minor release with a Kustomization which is
+			// not suspended
+			name:    "case 8",
+			labels:  map[string]string{"kustomize.toolkit.fluxcd.io/name": "kust1", "kustomize.toolkit.fluxcd.io/namespace": "default"},
+			release: "100.2.1",
+			owners:  []client.Object{kustomization("kust1", false)},
+			allowed: false,
+			message: "Kustomization default/kust1 is not suspended. Suspend it before the upgrade to 100.2.0.",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			handler := &Handler{
+				K8sClient: fakeK8sClient,
+				Logger:    microloggertest.New(),
+			}
+			for _, owner := range tc.owners {
+				err := fakeK8sClient.CtrlClient().Create(context.Background(), owner)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			oldCluster := unittest.DefaultCluster()
+			newCluster := unittest.DefaultCluster()
+			for k, v := range tc.labels {
+				oldCluster.Labels[k] = v
+				newCluster.Labels[k] = v
+			}
+			newCluster.Labels[label.Release] = tc.release
+			newCluster.SetAnnotations(tc.annotations)
+
+			var err error
+			if tc.deletion {
+				err = ValidateGitopsPaused(handler, guard, "Cluster", oldCluster, nil)
+			} else {
+				err = ValidateGitopsPaused(handler, guard, "Cluster", oldCluster, newCluster)
+			}
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed {
+				if !IsNotAllowed(err) {
+					t.Fatalf("expected not allowed error but got %v", err)
+				}
+				if !strings.Contains(err.Error(), tc.message) {
+					t.Fatalf("expected error to contain %q but got %v", tc.message, err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseGitopsGuard(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+
+		valid bool
+	}{
+		{
+			// Valid guard
+			name: "case 0",
+			content: `argoCDNamespace: gitops
+rules:
+- kinds: [Cluster, G8sControlPlane]
+  majorUpgrade: true
+  deletion: true
+- fields:
+  - spec.paused
+  - metadata.labels["release.giantswarm.io/version"]
+`,
+			valid: true,
+		},
+		{
+			// Rule matching no changes
+			name:    "case 1",
+			content: "rules:\n- kinds: [Cluster]\n",
+			valid:   false,
+		},
+		{
+			// Unterminated key in a field path
+			name:    "case 2",
+			content: "rules:\n- fields: ['metadata.labels[\"release.giantswarm.io/version']\n",
+			valid:   false,
+		},
+		{
+			// Unknown field
+			name:    "case 3",
+			content: "rules:\n- upgrade: true\n",
+			valid:   false,
+		},
+		{
+			// Deletion rule for a kind whose deletions are not validated
+			name:    "case 4",
+			content: "rules:\n- kinds: [Cluster, AWSCluster]\n  deletion: true\n",
+			valid:   false,
+		},
+		{
+			// Deletion rule for all kinds
+			name:    "case 5",
+			content: "rules:\n- deletion: true\n",
+			valid:   false,
+		},
+		{
+			// Upgrade to a release
+			name:    "case 6",
+			content: "rules:\n- kinds: [Cluster]\n  upgradeTo: 20.0.0\n",
+			valid:   true,
+		},
+		{
+			// Invalid upgrade version
+			name:    "case 7",
+			content: "rules:\n- kinds: [Cluster]\n  upgradeTo: twenty\n",
+			valid:   false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := ParsePolicy(policySection("gitopsGuard", tc.content), "")
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error but got %v", err)
+			}
+		})
+	}
+}
+
+func kustomization(name string, suspend bool) *kustomizev1beta2.Kustomization {
+	return &kustomizev1beta2.Kustomization{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: metav1.NamespaceDefault,
+		},
+		Spec: kustomizev1beta2.KustomizationSpec{
+			Suspend: suspend,
+		},
+	}
+}
+
+func gitopsOwner(apiVersion string, kind string, namespace string, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"spec": spec,
+		},
+	}
+}
@@ -14,6 +14,7 @@ import (
 	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/key"
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/mutator"
 )
@@ -80,6 +81,17 @@ func ReleaseVersion(meta metav1.Object, patch []mutator.PatchOperation) (*semver
 	return semver.New(version)
 }
 
+// Organization returns the organization of the object, preferring the value
+// of a patch defaulting the organization label.
+func Organization(meta metav1.Object, patch []mutator.PatchOperation) string {
+	for _, p := range patch {
+		if p.Path == fmt.Sprintf("/metadata/labels/%s", EscapeJSONPatchString(label.Organization)) {
+			return p.Value.(string)
+		}
+	}
+	return key.Organization(meta)
+}
+
 // Ensure the needed escapes are in place. See https://tools.ietf.org/html/rfc6901#section-3 .
 func EscapeJSONPatchString(input string) string {
 	input = strings.ReplaceAll(input, "~", "~0")
@@ -0,0 +1,163 @@
+package v1alpha3
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	serviceAccountUsernamePrefix = "system:serviceaccount:"
+)
+
+// RestrictedIdentities configures the identities acting on behalf of
+// customers, i.e. the Giant Swarm API and admins, per capability. Their
+// changes are restricted by the validators, while changes by other
+// identities, i.e. operators, are not validated.
+type RestrictedIdentities struct {
+	// LabelEdit identities must keep the Giant Swarm labels intact when they
+	// edit labels of the cluster resources.
+	LabelEdit *Identities `json:"labelEdit,omitempty"`
+	// ReleaseChange identities must follow the supported upgrade paths when
+	// they change the release of a cluster. Their changes are only allowed
+	// once the cluster transitioned and pass the pre-upgrade checks.
+	ReleaseChange *Identities `json:"releaseChange,omitempty"`
+	// StatusBypass lifts the cluster status and pre-upgrade checks of
+	// ReleaseChange identities. It only takes effect for identities which are
+	// also ReleaseChange identities, the release changes of all others are
+	// not validated anyway.
+	StatusBypass *Identities `json:"statusBypass,omitempty"`
+	// GitopsEdit identities are exempt from the restriction of out-of-band
+	// edits of objects managed by Flux, which applies to all identities
+	// except Flux itself.
+	GitopsEdit *Identities `json:"gitopsEdit,omitempty"`
+}
+
+// Identities is a set of users, groups and service accounts.
+type Identities struct {
+	Users  []string `json:"users,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+	// ServiceAccounts are given as `<namespace>/<name>`.
+	ServiceAccounts []string `json:"serviceAccounts,omitempty"`
+}
+
+// DefaultRestrictedIdentities restricts label edits and release changes of the
+// Giant Swarm API and the admin group, and exempts the admin group from the
+// restriction of out-of-band edits of Flux-managed objects.
+func DefaultRestrictedIdentities(adminGroup string) RestrictedIdentities {
+	admins := func() *Identities {
+		return &Identities{
+			Groups:          []string{adminGroup},
+			ServiceAccounts: []string{"giantswarm/api"},
+		}
+	}
+	return RestrictedIdentities{
+		LabelEdit:     admins(),
+		ReleaseChange: admins(),
+		StatusBypass:  &Identities{},
+		GitopsEdit: &Identities{
+			Groups: []string{adminGroup},
+		},
+	}
+}
+
+// withDefaults returns the identities with the capabilities which are not set
+// taken from defaults.
+func (p RestrictedIdentities) withDefaults(defaults RestrictedIdentities) RestrictedIdentities {
+	if p.LabelEdit == nil {
+		p.LabelEdit = defaults.LabelEdit
+	}
+	if p.ReleaseChange == nil {
+		p.ReleaseChange = defaults.ReleaseChange
+	}
+	if p.StatusBypass == nil {
+		p.StatusBypass = defaults.StatusBypass
+	}
+	if p.GitopsEdit == nil {
+		p.GitopsEdit = defaults.GitopsEdit
+	}
+	return p
+}
+
+// Validate returns an error if a service account is not given as
+// `<namespace>/<name>`.
+func (p RestrictedIdentities) Validate() error {
+	capabilities := []struct {
+		name       string
+		identities *Identities
+	}{
+		{"labelEdit", p.LabelEdit},
+		{"releaseChange", p.ReleaseChange},
+		{"statusBypass", p.StatusBypass},
+		{"gitopsEdit", p.GitopsEdit},
+	}
+	for _, capability := range capabilities {
+		err := capability.identities.Validate()
+		if err != nil {
+			return microerror.Maskf(parsingFailedError, "%s: %v", capability.name, err)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if a service account is not given as
+// `<namespace>/<name>`.
+func (i *Identities) Validate() error {
+	if i == nil {
+		return nil
+	}
+	for _, serviceAccount := range i.ServiceAccounts {
+		namespace, name, ok := strings.Cut(serviceAccount, "/")
+		if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+			return microerror.Maskf(parsingFailedError, "service account %q must be given as <namespace>/<name>", serviceAccount)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the user is one of the identities. No user matches
+// nil identities.
+func (i *Identities) Matches(userInfo authenticationv1.UserInfo) bool {
+	if i == nil {
+		return false
+	}
+	if Contains(i.Users, userInfo.Username) {
+		return true
+	}
+	for _, serviceAccount := range i.ServiceAccounts {
+		if serviceAccountUsername(serviceAccount) == userInfo.Username {
+			return true
+		}
+	}
+	for _, group := range userInfo.Groups {
+		if Contains(i.Groups, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateLabelEdit validates the label changes of identities with the label
+// edit capability.
+func ValidateLabelEdit(m *Handler, identities RestrictedIdentities, userInfo authenticationv1.UserInfo, old metav1.Object, new metav1.Object) error {
+	if !identities.LabelEdit.Matches(userInfo) {
+		return nil
+	}
+	err := ValidateLabelKeys(m, old, new)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	err = ValidateLabelValues(m, old, new)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	return nil
+}
+
+func serviceAccountUsername(serviceAccount string) string {
+	namespace, name, _ := strings.Cut(serviceAccount, "/")
+	return fmt.Sprintf("%s%s:%s", serviceAccountUsernamePrefix, namespace, name)
+}
@@ -0,0 +1,257 @@
+package v1alpha3
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
+	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
+)
+
+func TestValidateLabelEdit(t *testing.T) {
+	identities := RestrictedIdentities{
+		LabelEdit: &Identities{
+			Users:           []string{"jane"},
+			Groups:          []string{"customer-admins"},
+			ServiceAccounts: []string{"giantswarm/api"},
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		user         authenticationv1.UserInfo
+		organization string
+
+		allowed bool
+	}{
+		{
+			// Label edit by a restricted user
+			name:         "case 0",
+			user:         authenticationv1.UserInfo{Username: "jane"},
+			organization: "other",
+			allowed:      false,
+		},
+		{
+			// Label edit by a member of a privileged group
+			name:         "case 1",
+			user:         authenticationv1.UserInfo{Username: "john", Groups: []string{"system:authenticated", "customer-admins"}},
+			organization: "other",
+			allowed:      false,
+		},
+		{
+			// Label edit by a restricted service account
+			name:         "case 2",
+			user:         authenticationv1.UserInfo{Username: "system:serviceaccount:giantswarm:api"},
+			organization: "other",
+			allowed:      false,
+		},
+		{
+			// Label edit by a service account of the same name in another namespace
+			name:         "case 3",
+			user:         authenticationv1.UserInfo{Username: "system:serviceaccount:default:api"},
+			organization: "other",
+			allowed:      true,
+		},
+		{
+			// Label edit by an operator
+			name:         "case 4",
+			user:         authenticationv1.UserInfo{Username: "system:serviceaccount:giantswarm:cluster-operator"},
+			organization: "other",
+			allowed:      true,
+		},
+		{
+			// Unchanged labels
+			name:         "case 5",
+			user:         authenticationv1.UserInfo{Username: "jane"},
+			organization: "example-organization",
+			allowed:      true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			handler := &Handler{
+				K8sClient: unittest.FakeK8sClient(),
+				Logger:    microloggertest.New(),
+			}
+
+			oldCluster := unittest.DefaultCluster()
+			newCluster := unittest.DefaultCluster()
+			newCluster.Labels[label.Organization] = tc.organization
+
+			err := ValidateLabelEdit(handler, identities, tc.user, oldCluster, newCluster)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && !IsNotAllowed(err) {
+				t.Fatalf("expected not allowed error but got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateLabelEditAfterReleasePropagation(t *testing.T) {
+	awsControlPlane := unittest.DefaultAWSControlPlane()
+
+	testCases := []struct {
+		name string
+		obj  metav1.Object
+	}{
+		{
+			// AWSCluster
+			name: "case 0",
+			obj:  unittest.DefaultAWSCluster(),
+		},
+		{
+			// AWSControlPlane
+			name: "case 1",
+			obj:  &awsControlPlane,
+		},
+		{
+			// AWSMachineDeployment
+			name: "case 2",
+			obj:  unittest.DefaultAWSMachineDeployment(),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			handler := &Handler{
+				K8sClient: fakeK8sClient,
+				Logger:    microloggertest.New(),
+			}
+
+			cluster := unittest.DefaultCluster()
+			cluster.Labels[label.Release] = "100.1.0"
+			err := fakeK8sClient.CtrlClient().Create(context.Background(), cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			release := unittest.NamedRelease("v100.1.0")
+			err = fakeK8sClient.CtrlClient().Create(context.Background(), &release)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			oldLabels := tc.obj.GetLabels()
+			oldLabels[label.AWSOperatorVersion] = "6.0.0"
+			tc.obj.SetLabels(oldLabels)
+			newLabels := map[string]string{}
+			for k, v := range oldLabels {
+				newLabels[k] = v
+			}
+
+			// The mutation stage propagates the release and operator versions.
+			patch, err := MutateReleasePropagation(handler, tc.obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range patch {
+				k := strings.TrimPrefix(p.Path, "/metadata/labels/")
+				k = strings.ReplaceAll(strings.ReplaceAll(k, "~1", "/"), "~0", "~")
+				newLabels[k] = p.Value.(string)
+			}
+			if newLabels[label.AWSOperatorVersion] == "6.0.0" {
+				t.Fatalf("expected label %s to be propagated", label.AWSOperatorVersion)
+			}
+
+			oldObj := &metav1.ObjectMeta{Name: tc.obj.GetName(), Labels: oldLabels}
+			newObj := &metav1.ObjectMeta{Name: tc.obj.GetName(), Labels: newLabels}
+			admin := authenticationv1.UserInfo{Username: "john", Groups: []string{"customer-admins"}}
+			err = ValidateLabelEdit(handler, DefaultRestrictedIdentities("customer-admins"), admin, oldObj, newObj)
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+func TestParseRestrictedIdentities(t *testing.T) {
+	admin := authenticationv1.UserInfo{Username: "john", Groups: []string{"customer-admins"}}
+	api := authenticationv1.UserInfo{Username: "system:serviceaccount:giantswarm:api"}
+
+	testCases := []struct {
+		name    string
+		content string
+
+		valid     bool
+		labelEdit []authenticationv1.UserInfo
+		matches   bool
+	}{
+		{
+			// Release changes are configured, label edits keep the defaults
+			name: "case 0",
+			content: `releaseChange:
+  users: [jane]
+statusBypass:
+  groups: [giantswarm-admins]
+`,
+			valid:     true,
+			labelEdit: []authenticationv1.UserInfo{admin, api},
+			matches:   true,
+		},
+		{
+			// Label edits are replaced
+			name:      "case 1",
+			content:   "labelEdit:\n  serviceAccounts: [giantswarm/happa]\n",
+			valid:     true,
+			labelEdit: []authenticationv1.UserInfo{admin, api},
+			matches:   false,
+		},
+		{
+			// Service account without namespace
+			name:    "case 2",
+			content: "gitopsEdit:\n  serviceAccounts: [api]\n",
+			valid:   false,
+		},
+		{
+			// Unknown capability
+			name:    "case 3",
+			content: "labelEdits:\n  users: [jane]\n",
+			valid:   false,
+		},
+		{
+			// Label edits are replaced by a user, dropping the default group and service account
+			name:      "case 4",
+			content:   "labelEdit:\n  users: [alice]\n",
+			valid:     true,
+			labelEdit: []authenticationv1.UserInfo{admin, api},
+			matches:   false,
+		},
+		{
+			// Label edits are replaced by nobody
+			name:      "case 5",
+			content:   "labelEdit: {}\n",
+			valid:     true,
+			labelEdit: []authenticationv1.UserInfo{admin, api},
+			matches:   false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			policy, err := ParsePolicy(policySection("restrictedIdentities", tc.content), "customer-admins")
+			if !tc.valid {
+				if !IsInvalidConfig(err) {
+					t.Fatalf("expected invalid config error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			for _, user := range tc.labelEdit {
+				if policy.RestrictedIdentities.LabelEdit.Matches(user) != tc.matches {
+					t.Fatalf("expected label edit match of %s to be %v", user.Username, tc.matches)
+				}
+			}
+		})
+	}
+}
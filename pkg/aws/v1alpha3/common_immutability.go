@@ -0,0 +1,140 @@
+package v1alpha3
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ImmutabilityPolicy lists fields which must not change on update.
+type ImmutabilityPolicy struct {
+	Rules []ImmutabilityRule `json:"rules"`
+}
+
+// ImmutabilityRule makes fields of the given kinds immutable.
+type ImmutabilityRule struct {
+	// Kinds the rule applies to.
+	Kinds []string `json:"kinds"`
+	// Fields are paths like in GitopsRule, e.g. `spec.provider.region`.
+	Fields []string `json:"fields"`
+	// Exemptions allow certain changes of the fields.
+	Exemptions ImmutabilityExemptions `json:"exemptions,omitempty"`
+}
+
+// ImmutabilityExemptions describe changes of immutable fields which are
+// allowed nevertheless.
+type ImmutabilityExemptions struct {
+	// Identities may change the fields.
+	Identities Identities `json:"identities,omitempty"`
+	// Initialization allows setting fields which are empty, e.g. when they are
+	// defaulted by a mutator.
+	Initialization bool `json:"initialization,omitempty"`
+}
+
+// DefaultImmutabilityPolicy protects fields whose change breaks existing
+// clusters.
+func DefaultImmutabilityPolicy() ImmutabilityPolicy {
+	return ImmutabilityPolicy{
+		Rules: []ImmutabilityRule{
+			{
+				Kinds: []string{"AWSCluster"},
+				Fields: []string{
+					"spec.provider.region",
+					"spec.provider.pods.cidrBlock",
+					"spec.cluster.dns.domain",
+				},
+				Exemptions: ImmutabilityExemptions{Initialization: true},
+			},
+			{
+				Kinds:  []string{"AWSMachineDeployment"},
+				Fields: []string{"spec.provider.availabilityZones"},
+			},
+			{
+				Kinds:  []string{"MachineDeployment"},
+				Fields: []string{"spec.clusterName"},
+			},
+		},
+	}
+}
+
+// Validate returns an error if a rule has no kinds, no fields, an invalid
+// field path or an invalid exempted service account.
+func (p ImmutabilityPolicy) Validate() error {
+	for i, rule := range p.Rules {
+		if len(rule.Kinds) == 0 {
+			return microerror.Maskf(parsingFailedError, "rule %d has no kinds", i)
+		}
+		if len(rule.Fields) == 0 {
+			return microerror.Maskf(parsingFailedError, "rule %d has no fields", i)
+		}
+		for _, field := range rule.Fields {
+			if _, err := parseFieldPath(field); err != nil {
+				return microerror.Maskf(parsingFailedError, "rule %d: %v", i, err)
+			}
+		}
+		err := rule.Exemptions.Identities.Validate()
+		if err != nil {
+			return microerror.Maskf(parsingFailedError, "rule %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// ValidateImmutableFields returns a notAllowedError listing the immutable
+// fields of the kind which changed from oldObj to obj and are not exempted.
+func ValidateImmutableFields(policy ImmutabilityPolicy, kind string, userInfo authenticationv1.UserInfo, oldObj *unstructured.Unstructured, obj *unstructured.Unstructured) error {
+	var changes []string
+	for _, rule := range policy.Rules {
+		if !Contains(rule.Kinds, kind) || rule.Exemptions.Identities.Matches(userInfo) {
+			continue
+		}
+		for _, field := range rule.Fields {
+			path, err := parseFieldPath(field)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+			oldValue, _, _ := unstructured.NestedFieldNoCopy(oldObj.Object, path...)
+			newValue, _, _ := unstructured.NestedFieldNoCopy(obj.Object, path...)
+			if reflect.DeepEqual(oldValue, newValue) {
+				continue
+			}
+			if rule.Exemptions.Initialization && isEmptyValue(oldValue) {
+				continue
+			}
+			changes = appendUnique(changes, fmt.Sprintf("%s from %v to %v", field, formatValue(oldValue), formatValue(newValue)))
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return microerror.Maskf(notAllowedError, "Immutable fields of %s %s/%s can not be changed: %s.",
+		kind,
+		obj.GetNamespace(),
+		obj.GetName(),
+		strings.Join(changes, ", "),
+	)
+}
+
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func formatValue(value interface{}) string {
+	if isEmptyValue(value) {
+		return `""`
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,185 @@
+package v1alpha3
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	infrastructurev1alpha3 "github.com/giantswarm/apiextensions/v6/pkg/apis/infrastructure/v1alpha3"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
+)
+
+func TestValidateImmutableFields(t *testing.T) {
+	policy := DefaultImmutabilityPolicy()
+	policy.Rules = append(policy.Rules, ImmutabilityRule{
+		Kinds:  []string{"AWSCluster"},
+		Fields: []string{`metadata.annotations["giantswarm.io/fixed"]`},
+		Exemptions: ImmutabilityExemptions{
+			Identities: Identities{Groups: []string{"giantswarm-admins"}},
+		},
+	})
+
+	testCases := []struct {
+		name   string
+		user   authenticationv1.UserInfo
+		mutate func(oldAWSCluster *infrastructurev1alpha3.AWSCluster, awsCluster *infrastructurev1alpha3.AWSCluster)
+
+		allowed bool
+		message string
+	}{
+		{
+			// Change of a mutable field
+			name: "case 0",
+			mutate: func(oldAWSCluster *infrastructurev1alpha3.AWSCluster, awsCluster *infrastructurev1alpha3.AWSCluster) {
+				awsCluster.Spec.Cluster.Description = "changed"
+			},
+			allowed: true,
+		},
+		{
+			// Change of the region
+			name: "case 1",
+			mutate: func(oldAWSCluster *infrastructurev1alpha3.AWSCluster, awsCluster *infrastructurev1alpha3.AWSCluster) {
+				awsCluster.Spec.Provider.Region = "eu-west-1"
+			},
+			allowed: false,
+			message: "spec.provider.region from eu-central-1 to eu-west-1",
+		},
+		{
+			// Change of the pod CIDR and the DNS domain are both reported
+			name: "case 2",
+			mutate: func(oldAWSCluster *infrastructurev1alpha3.AWSCluster, awsCluster *infrastructurev1alpha3.AWSCluster) {
+				awsCluster.Spec.Provider.Pods.CIDRBlock = "10.20.0.0/16"
+				awsCluster.Spec.Cluster.DNS.Domain = "example.com"
+			},
+			allowed: false,
+			message: "spec.provider.pods.cidrBlock from 10.10.0.0/16 to 10.20.0.0/16, spec.cluster.dns.domain from g8s.example.com to example.com",
+		},
+		{
+			// Initialization of an empty pod CIDR
+			name: "case 3",
+			mutate: func(oldAWSCluster *infrastructurev1alpha3.AWSCluster, awsCluster *infrastructurev1alpha3.AWSCluster) {
+				oldAWSCluster.Spec.Provider.Pods.CIDRBlock = ""
+			},
+			allowed: true,
+		},
+		{
+			// Removal of the pod CIDR
+			name: "case 4",
+			mutate: func(oldAWSCluster *infrastructurev1alpha3.AWSCluster, awsCluster *infrastructurev1alpha3.AWSCluster) {
+				awsCluster.Spec.Provider.Pods.CIDRBlock = ""
+			},
+			allowed: false,
+			message: `spec.provider.pods.cidrBlock from 10.10.0.0/16 to ""`,
+		},
+		{
+			// Change of an annotation by a user
+			name: "case 5",
+			user: authenticationv1.UserInfo{Username: "jane"},
+			mutate: func(oldAWSCluster *infrastructurev1alpha3.AWSCluster, awsCluster *infrastructurev1alpha3.AWSCluster) {
+				oldAWSCluster.SetAnnotations(map[string]string{"giantswarm.io/fixed": "a"})
+				awsCluster.SetAnnotations(map[string]string{"giantswarm.io/fixed": "b"})
+			},
+			allowed: false,
+			message: `metadata.annotations["giantswarm.io/fixed"] from a to b`,
+		},
+		{
+			// Change of an annotation by an exempted group
+			name: "case 6",
+			user: authenticationv1.UserInfo{Username: "jane", Groups: []string{"giantswarm-admins"}},
+			mutate: func(oldAWSCluster *infrastructurev1alpha3.AWSCluster, awsCluster *infrastructurev1alpha3.AWSCluster) {
+				oldAWSCluster.SetAnnotations(map[string]string{"giantswarm.io/fixed": "a"})
+				awsCluster.SetAnnotations(map[string]string{"giantswarm.io/fixed": "b"})
+			},
+			allowed: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			oldAWSCluster := unittest.DefaultAWSCluster()
+			awsCluster := unittest.DefaultAWSCluster()
+			tc.mutate(oldAWSCluster, awsCluster)
+
+			oldObj, err := toUnstructured(oldAWSCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			obj, err := toUnstructured(awsCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = ValidateImmutableFields(policy, "AWSCluster", tc.user, oldObj, obj)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed {
+				if !IsNotAllowed(err) {
+					t.Fatalf("expected not allowed error but got %v", err)
+				}
+				if !strings.Contains(err.Error(), tc.message) {
+					t.Fatalf("expected error to contain %q but got %v", tc.message, err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseImmutabilityPolicy(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+
+		valid bool
+	}{
+		{
+			// Valid policy
+			name: "case 0",
+			content: `rules:
+- kinds: [AWSCluster]
+  fields: [spec.provider.region]
+  exemptions:
+    initialization: true
+    identities:
+      serviceAccounts: [giantswarm/cluster-operator]
+`,
+			valid: true,
+		},
+		{
+			// Rule without kinds
+			name:    "case 1",
+			content: "rules:\n- fields: [spec.provider.region]\n",
+			valid:   false,
+		},
+		{
+			// Exempted service account without namespace
+			name:    "case 2",
+			content: "rules:\n- kinds: [AWSCluster]\n  fields: [spec.provider.region]\n  exemptions:\n    identities:\n      serviceAccounts: [cluster-operator]\n",
+			valid:   false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := ParsePolicy(policySection("immutability", tc.content), "")
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error but got %v", err)
+			}
+		})
+	}
+}
+
+func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
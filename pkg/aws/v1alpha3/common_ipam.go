@@ -0,0 +1,236 @@
+package v1alpha3
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	infrastructurev1alpha3 "github.com/giantswarm/apiextensions/v6/pkg/apis/infrastructure/v1alpha3"
+	"github.com/giantswarm/k8smetadata/pkg/annotation"
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/cidr"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/key"
+)
+
+// Reservation is a network range in use by the installation or by a single
+// cluster.
+type Reservation struct {
+	// ClusterID is the cluster the network range belongs to. Reservations
+	// without ClusterID are reserved for the whole installation, e.g. the
+	// Docker CIDR or NetworkPools from which node subnets are allocated.
+	// Reservations of a cluster, e.g. its pod CIDRs, only have to be unique
+	// within that cluster, because they are not routed outside of its VPC.
+	ClusterID string
+	// NetworkPool is the name of the NetworkPool reserving the network range.
+	// For reservations of a cluster it is the NetworkPool its nodes use. Since
+	// a NetworkPool is only routed within the VPCs of the clusters using it,
+	// it only conflicts with the reservations of these clusters.
+	NetworkPool string
+	Network     *net.IPNet
+	// Owner describes what reserves the network range.
+	Owner string
+}
+
+// Reservations is the allocation model of all network ranges known to the
+// admission controller.
+type Reservations []Reservation
+
+// GlobalReservations returns the reservations of the network ranges
+// configured for the installation. Empty ranges are skipped.
+func GlobalReservations(dockerCIDR string, ipamNetworkCIDR string, kubernetesClusterIPRange string) (Reservations, error) {
+	var reservations Reservations
+
+	ranges := []struct {
+		cidr  string
+		owner string
+	}{
+		{cidr: dockerCIDR, owner: "Docker CIDR"},
+		{cidr: ipamNetworkCIDR, owner: "IPAM network CIDR"},
+		{cidr: kubernetesClusterIPRange, owner: "Kubernetes cluster IP range"},
+	}
+	for _, r := range ranges {
+		if r.cidr == "" {
+			continue
+		}
+		networks, err := cidr.ParseList(r.cidr)
+		if err != nil {
+			return nil, microerror.Maskf(invalidConfigError, "%s %s is invalid: %v", r.owner, r.cidr, err)
+		}
+		for _, network := range networks {
+			reservations = append(reservations, Reservation{Network: network, Owner: r.owner})
+		}
+	}
+
+	return reservations, nil
+}
+
+// FetchReservations returns the given global reservations together with the
+// network ranges of all NetworkPools, AWSCluster pod CIDRs and Cilium pod CIDR
+// annotations of Clusters. Objects with unparsable network ranges are skipped
+// since they can not be fixed by the request under validation.
+func FetchReservations(m *Handler, global Reservations) (Reservations, error) {
+	reservations := append(Reservations{}, global...)
+	ctx := context.Background()
+
+	var networkPools infrastructurev1alpha3.NetworkPoolList
+	err := m.K8sClient.CtrlClient().List(ctx, &networkPools)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	for i := range networkPools.Items {
+		networkPool := &networkPools.Items[i]
+		reservations = m.appendReservation(reservations, networkPool.Spec.CIDRBlock, Reservation{NetworkPool: networkPool.GetName(), Owner: NetworkPoolOwner(networkPool)})
+	}
+
+	var awsClusters infrastructurev1alpha3.AWSClusterList
+	err = m.K8sClient.CtrlClient().List(ctx, &awsClusters)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	networkPoolOf := map[string]string{}
+	for i := range awsClusters.Items {
+		awsCluster := &awsClusters.Items[i]
+		networkPoolOf[ClusterID(awsCluster)] = awsCluster.Spec.Provider.Nodes.NetworkPool
+		reservations = m.appendReservation(reservations, awsCluster.Spec.Provider.Pods.CIDRBlock, Reservation{ClusterID: ClusterID(awsCluster), NetworkPool: awsCluster.Spec.Provider.Nodes.NetworkPool, Owner: PodCIDROwner(awsCluster)})
+	}
+
+	var clusters capi.ClusterList
+	err = m.K8sClient.CtrlClient().List(ctx, &clusters)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		reservations = m.appendReservation(reservations, cluster.GetAnnotations()[annotation.CiliumPodCidr], Reservation{ClusterID: ClusterID(cluster), NetworkPool: networkPoolOf[ClusterID(cluster)], Owner: CiliumPodCIDROwner(cluster)})
+	}
+
+	return reservations, nil
+}
+
+// appendReservation appends a copy of the reservation for each network range
+// of the CIDR list.
+func (m *Handler) appendReservation(reservations Reservations, cidrList string, reservation Reservation) Reservations {
+	if cidrList == "" {
+		return reservations
+	}
+	networks, err := cidr.ParseList(cidrList)
+	if err != nil {
+		m.Logger.Log("level", "debug", "message", fmt.Sprintf("Ignoring invalid CIDR %s of %s: %v", cidrList, reservation.Owner, err))
+		return reservations
+	}
+	for _, network := range networks {
+		reservation.Network = network
+		reservations = append(reservations, reservation)
+	}
+	return reservations
+}
+
+// Conflict returns the first reservation the candidate overlaps with.
+// Reservations of the candidate's owner are ignored so that updated objects
+// do not conflict with their current state. Global reservations conflict with
+// each other and with all reservations of clusters, except for NetworkPools,
+// which only conflict with the reservations of the clusters using them.
+// Reservations of different clusters never conflict. Reservations of the
+// other address family never conflict, so that the IPv4 and IPv6 ranges of
+// dual-stack networks are checked independently.
+func (r Reservations) Conflict(candidate Reservation) (Reservation, bool) {
+	for _, reservation := range r {
+		if reservation.Owner == candidate.Owner {
+			continue
+		}
+		if candidate.ClusterID != "" && reservation.ClusterID != "" && candidate.ClusterID != reservation.ClusterID {
+			continue
+		}
+		if !candidate.routedWith(reservation) || !reservation.routedWith(candidate) {
+			continue
+		}
+		overlap, err := cidr.Overlap(reservation.Network, candidate.Network)
+		if cidr.IsMixedFamily(err) {
+			continue
+		}
+		if overlap {
+			return reservation, true
+		}
+	}
+	return Reservation{}, false
+}
+
+// routedWith returns false if the reservation is a NetworkPool and the other
+// reservation belongs to a cluster not using it.
+func (r Reservation) routedWith(other Reservation) bool {
+	if r.ClusterID != "" || r.NetworkPool == "" || other.ClusterID == "" {
+		return true
+	}
+	return other.NetworkPool == r.NetworkPool
+}
+
+// Allocate returns the first network with the given prefix length within the
+// supernet which overlaps none of the reservations, regardless of the cluster
+// they belong to. Unlike Conflict, this keeps allocated ranges unique across
+// the installation. Reservations of the owner are ignored.
+func (r Reservations) Allocate(supernet *net.IPNet, prefixLength int, owner string) (*net.IPNet, error) {
+	ones, bits := supernet.Mask.Size()
+	if prefixLength < ones || prefixLength > bits {
+		return nil, microerror.Maskf(invalidConfigError, "prefix length /%d does not fit into supernet %s", prefixLength, supernet.String())
+	}
+
+	mask := net.CIDRMask(prefixLength, bits)
+	candidate := &net.IPNet{IP: supernet.IP.Mask(mask), Mask: mask}
+	for supernet.Contains(candidate.IP) {
+		reservation, ok := r.overlapping(candidate, owner)
+		if !ok {
+			return candidate, nil
+		}
+		// Skip every candidate covered by the overlapping reservation.
+		candidate = &net.IPNet{IP: cidr.Last(reservation.Network).Mask(mask), Mask: mask}
+		candidate, ok = cidr.Next(candidate)
+		if !ok {
+			break
+		}
+	}
+
+	return nil, microerror.Maskf(allocationFailedError, "no free /%d network left in %s", prefixLength, supernet.String())
+}
+
+func (r Reservations) overlapping(network *net.IPNet, owner string) (Reservation, bool) {
+	for _, reservation := range r {
+		if reservation.Owner == owner {
+			continue
+		}
+		overlap, err := cidr.Overlap(reservation.Network, network)
+		if cidr.IsMixedFamily(err) {
+			continue
+		}
+		if overlap {
+			return reservation, true
+		}
+	}
+	return Reservation{}, false
+}
+
+// ClusterID returns the ID of the cluster the object belongs to.
+func ClusterID(meta metav1.Object) string {
+	if id := key.Cluster(meta); id != "" {
+		return id
+	}
+	return meta.GetName()
+}
+
+// NetworkPoolOwner describes the reservation of a NetworkPool.
+func NetworkPoolOwner(meta metav1.Object) string {
+	return fmt.Sprintf("NetworkPool %s/%s", meta.GetNamespace(), meta.GetName())
+}
+
+// PodCIDROwner describes the reservation of the pod CIDR of an AWSCluster.
+func PodCIDROwner(meta metav1.Object) string {
+	return fmt.Sprintf("pod CIDR of AWSCluster %s/%s", meta.GetNamespace(), meta.GetName())
+}
+
+// CiliumPodCIDROwner describes the reservation of the Cilium pod CIDR of a
+// Cluster.
+func CiliumPodCIDROwner(meta metav1.Object) string {
+	return fmt.Sprintf("Cilium pod CIDR of Cluster %s/%s", meta.GetNamespace(), meta.GetName())
+}
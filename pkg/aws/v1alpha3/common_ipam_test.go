@@ -0,0 +1,251 @@
+package v1alpha3
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/k8smetadata/pkg/annotation"
+	"github.com/giantswarm/micrologger/microloggertest"
+
+	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
+)
+
+func TestReservationsConflict(t *testing.T) {
+	networkPool := unittest.DefaultNetworkPool("10.100.0.0/16")
+
+	testCases := []struct {
+		name        string
+		clusterID   string
+		networkPool string
+		owner       string
+		cidr        string
+
+		conflict      bool
+		conflictOwner string
+	}{
+		{
+			// Global range overlapping a global range
+			name:          "case 0",
+			owner:         "NetworkPool default/new",
+			cidr:          "172.17.0.0/24",
+			conflict:      true,
+			conflictOwner: "Docker CIDR",
+		},
+		{
+			// Global range overlapping a NetworkPool
+			name:          "case 1",
+			owner:         "NetworkPool default/new",
+			cidr:          "10.100.0.0/16",
+			conflict:      true,
+			conflictOwner: NetworkPoolOwner(networkPool),
+		},
+		{
+			// NetworkPool overlaps the pod CIDR of a cluster using it
+			name:          "case 2",
+			networkPool:   "new",
+			owner:         "NetworkPool default/new",
+			cidr:          "10.2.0.0/16",
+			conflict:      true,
+			conflictOwner: "pod CIDR of AWSCluster default/" + unittest.DefaultClusterID,
+		},
+		{
+			// Cluster range overlapping a NetworkPool the cluster does not use
+			name:      "case 3",
+			clusterID: "other",
+			owner:     "pod CIDR of AWSCluster default/other",
+			cidr:      "10.100.0.0/24",
+			conflict:  false,
+		},
+		{
+			// Cluster range overlapping a range of another cluster
+			name:      "case 4",
+			clusterID: "other",
+			owner:     "pod CIDR of AWSCluster default/other",
+			cidr:      "10.2.0.0/16",
+			conflict:  false,
+		},
+		{
+			// Cluster range overlapping a range of the same cluster
+			name:          "case 5",
+			clusterID:     unittest.DefaultClusterID,
+			owner:         "Cilium pod CIDR of Cluster default/" + unittest.DefaultClusterID,
+			cidr:          "10.2.0.0/16",
+			conflict:      true,
+			conflictOwner: "pod CIDR of AWSCluster default/" + unittest.DefaultClusterID,
+		},
+		{
+			// Reservations of the owner itself are ignored
+			name:      "case 6",
+			clusterID: unittest.DefaultClusterID,
+			owner:     "pod CIDR of AWSCluster default/" + unittest.DefaultClusterID,
+			cidr:      "10.2.0.0/15",
+			conflict:  false,
+		},
+		{
+			// Ranges of the other address family do not conflict
+			name:     "case 7",
+			owner:    "NetworkPool default/new",
+			cidr:     "fd00:10::/56",
+			conflict: false,
+		},
+		{
+			// NetworkPool overlaps the pod CIDR of a cluster not using it
+			name:        "case 8",
+			networkPool: "unused",
+			owner:       "NetworkPool default/unused",
+			cidr:        "10.2.0.0/16",
+			conflict:    false,
+		},
+		{
+			// Cluster range overlapping the NetworkPool the cluster uses
+			name:          "case 9",
+			clusterID:     "other",
+			networkPool:   networkPool.GetName(),
+			owner:         "Cilium pod CIDR of Cluster default/other",
+			cidr:          "10.100.0.0/24",
+			conflict:      true,
+			conflictOwner: NetworkPoolOwner(networkPool),
+		},
+		{
+			// Cluster range overlapping a global range which is not a NetworkPool
+			name:          "case 10",
+			clusterID:     "other",
+			owner:         "Cilium pod CIDR of Cluster default/other",
+			cidr:          "172.31.0.0/24",
+			conflict:      true,
+			conflictOwner: "Kubernetes cluster IP range",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			handler := &Handler{
+				K8sClient: fakeK8sClient,
+				Logger:    microloggertest.New(),
+			}
+
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.Spec.Provider.Pods.CIDRBlock = "10.2.0.0/16"
+			awsCluster.Spec.Provider.Nodes.NetworkPool = "new"
+			cluster := unittest.DefaultCluster()
+			cluster.SetAnnotations(map[string]string{annotation.CiliumPodCidr: "invalid"})
+			err := fakeK8sClient.CtrlClient().Create(context.Background(), networkPool.DeepCopy())
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = fakeK8sClient.CtrlClient().Create(context.Background(), awsCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = fakeK8sClient.CtrlClient().Create(context.Background(), cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			global, err := GlobalReservations("172.17.0.1/16", "10.1.0.0/16", "172.31.0.0/16")
+			if err != nil {
+				t.Fatal(err)
+			}
+			reservations, err := FetchReservations(handler, global)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, network, err := net.ParseCIDR(tc.cidr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			candidate := Reservation{
+				ClusterID:   tc.clusterID,
+				NetworkPool: tc.networkPool,
+				Network:     network,
+				Owner:       tc.owner,
+			}
+			conflict, ok := reservations.Conflict(candidate)
+			if ok != tc.conflict {
+				t.Fatalf("expected conflict %t but got %t (%s)", tc.conflict, ok, conflict.Owner)
+			}
+			if ok && tc.conflictOwner != "" && conflict.Owner != tc.conflictOwner {
+				t.Fatalf("expected conflict with %s but got %s", tc.conflictOwner, conflict.Owner)
+			}
+		})
+	}
+}
+
+func TestReservationsAllocate(t *testing.T) {
+	testCases := []struct {
+		name         string
+		reserved     []string
+		supernet     string
+		prefixLength int
+
+		expected      string
+		expectedError bool
+	}{
+		{
+			// Empty supernet
+			name:         "case 0",
+			supernet:     "100.64.0.0/10",
+			prefixLength: 16,
+			expected:     "100.64.0.0/16",
+		},
+		{
+			// Larger reservations are skipped as a whole
+			name:         "case 1",
+			reserved:     []string{"100.64.0.0/12", "100.80.0.0/24"},
+			supernet:     "100.64.0.0/10",
+			prefixLength: 16,
+			expected:     "100.81.0.0/16",
+		},
+		{
+			// Exhausted supernet
+			name:          "case 2",
+			reserved:      []string{"100.64.0.0/10"},
+			supernet:      "100.64.0.0/11",
+			prefixLength:  16,
+			expectedError: true,
+		},
+		{
+			// IPv6 supernet ignores IPv4 reservations
+			name:         "case 3",
+			reserved:     []string{"100.64.0.0/10", "fd00:10::/56"},
+			supernet:     "fd00:10::/48",
+			prefixLength: 56,
+			expected:     "fd00:10:0:100::/56",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var reservations Reservations
+			for j, reserved := range tc.reserved {
+				_, network, err := net.ParseCIDR(reserved)
+				if err != nil {
+					t.Fatal(err)
+				}
+				reservations = append(reservations, Reservation{ClusterID: strconv.Itoa(j), Network: network, Owner: reserved})
+			}
+			_, supernet, err := net.ParseCIDR(tc.supernet)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			network, err := reservations.Allocate(supernet, tc.prefixLength, "new")
+			if tc.expectedError {
+				if !IsAllocationFailed(err) {
+					t.Fatalf("expected allocation failed error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if network.String() != tc.expected {
+				t.Fatalf("expected %s but got %s", tc.expected, network.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package v1alpha3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/giantswarm/k8smetadata/pkg/annotation"
+	"github.com/giantswarm/microerror"
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/internal/normalize"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/key"
+)
+
+const (
+	// AnnotationMaintenanceWindows restricts scheduled upgrades of a cluster to
+	// maintenance windows. It can be set on the Organization and on the Cluster,
+	// windows of the Cluster replace windows of the Organization. The value is
+	// a semicolon separated list of windows in UTC, e.g.
+	// `Mon-Fri 02:00-06:00; Sat,Sun 22:00-04:00`.
+	AnnotationMaintenanceWindows = "giantswarm.io/maintenance-windows"
+	// AnnotationMaintenanceFreezes forbids scheduled upgrades of a cluster
+	// during freeze periods. It can be set on the Organization and on the
+	// Cluster, the periods of both apply. The value is a comma separated list of
+	// inclusive date ranges in UTC, either once (`2024-05-01/2024-05-03`) or
+	// every year (`12-20/01-06`).
+	AnnotationMaintenanceFreezes = "giantswarm.io/maintenance-freezes"
+	// AnnotationMaintenanceUpgradeSpacing is the minimum time between scheduled
+	// upgrades of clusters in the same namespace, so that their rollouts do not
+	// overlap. It can be set on the Organization and on the Cluster, the
+	// spacing of the Cluster replaces the spacing of the Organization. The
+	// value is a duration, e.g. `2h`.
+	AnnotationMaintenanceUpgradeSpacing = "giantswarm.io/maintenance-upgrade-spacing"
+
+	// DefaultScheduledUpgradeSpacing is the upgrade spacing if neither the
+	// Cluster nor its Organization sets one.
+	DefaultScheduledUpgradeSpacing = 2 * time.Hour
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// MaintenancePolicy restricts the times at which a cluster can be upgraded.
+type MaintenancePolicy struct {
+	// Windows are the allowed times. No windows allow any time.
+	Windows []MaintenanceWindow
+	// Freezes are the forbidden periods.
+	Freezes []FreezePeriod
+	// UpgradeSpacing is the minimum time between scheduled upgrades of
+	// clusters in the same namespace. Nil means the default.
+	UpgradeSpacing *time.Duration
+}
+
+// MaintenanceWindow is a daily time range on a set of weekdays. A window whose
+// end is not after its start ends on the following day.
+type MaintenanceWindow struct {
+	Description string
+	Days        map[time.Weekday]bool
+	// Start and End are offsets since midnight UTC.
+	Start time.Duration
+	End   time.Duration
+}
+
+// FreezePeriod is an inclusive range of days. Recurring periods apply every
+// year and may span the turn of the year.
+type FreezePeriod struct {
+	Description string
+	Recurring   bool
+	Start       time.Time
+	End         time.Time
+}
+
+// ParseMaintenancePolicy parses the maintenance annotations.
+func ParseMaintenancePolicy(annotations map[string]string) (MaintenancePolicy, error) {
+	var policy MaintenancePolicy
+
+	if value := strings.TrimSpace(annotations[AnnotationMaintenanceWindows]); value != "" {
+		for _, w := range strings.Split(value, ";") {
+			window, err := parseMaintenanceWindow(strings.TrimSpace(w))
+			if err != nil {
+				return MaintenancePolicy{}, microerror.Mask(err)
+			}
+			policy.Windows = append(policy.Windows, window)
+		}
+	}
+	if value := strings.TrimSpace(annotations[AnnotationMaintenanceFreezes]); value != "" {
+		for _, f := range strings.Split(value, ",") {
+			freeze, err := parseFreezePeriod(strings.TrimSpace(f))
+			if err != nil {
+				return MaintenancePolicy{}, microerror.Mask(err)
+			}
+			policy.Freezes = append(policy.Freezes, freeze)
+		}
+	}
+	if value := strings.TrimSpace(annotations[AnnotationMaintenanceUpgradeSpacing]); value != "" {
+		spacing, err := time.ParseDuration(value)
+		if err != nil || spacing < 0 {
+			return MaintenancePolicy{}, microerror.Maskf(parsingFailedError, "annotation %s value %q is invalid: it must be a non-negative duration, e.g. `2h`", AnnotationMaintenanceUpgradeSpacing, value)
+		}
+		policy.UpgradeSpacing = &spacing
+	}
+
+	return policy, nil
+}
+
+// Spacing returns the minimum time between scheduled upgrades of clusters in
+// the same namespace.
+func (p MaintenancePolicy) Spacing() time.Duration {
+	if p.UpgradeSpacing == nil {
+		return DefaultScheduledUpgradeSpacing
+	}
+	return *p.UpgradeSpacing
+}
+
+func parseMaintenanceWindow(s string) (MaintenanceWindow, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return MaintenanceWindow{}, microerror.Maskf(parsingFailedError, "annotation %s window %q must consist of days and a time range, e.g. `Mon-Fri 02:00-06:00`", AnnotationMaintenanceWindows, s)
+	}
+
+	days := map[time.Weekday]bool{}
+	if fields[0] == "*" {
+		for _, day := range weekdays {
+			days[day] = true
+		}
+	} else {
+		for _, d := range strings.Split(fields[0], ",") {
+			from, to, isRange := strings.Cut(d, "-")
+			start, ok := weekdays[strings.ToLower(from)]
+			if !ok {
+				return MaintenanceWindow{}, microerror.Maskf(parsingFailedError, "annotation %s window %q has unknown day %q", AnnotationMaintenanceWindows, s, from)
+			}
+			end := start
+			if isRange {
+				end, ok = weekdays[strings.ToLower(to)]
+				if !ok {
+					return MaintenanceWindow{}, microerror.Maskf(parsingFailedError, "annotation %s window %q has unknown day %q", AnnotationMaintenanceWindows, s, to)
+				}
+			}
+			for day := start; ; day = (day + 1) % 7 {
+				days[day] = true
+				if day == end {
+					break
+				}
+			}
+		}
+	}
+
+	from, to, ok := strings.Cut(fields[1], "-")
+	if !ok {
+		return MaintenanceWindow{}, microerror.Maskf(parsingFailedError, "annotation %s window %q time range %q must be of the form `HH:MM-HH:MM`", AnnotationMaintenanceWindows, s, fields[1])
+	}
+	start, err := parseTimeOfDay(s, from)
+	if err != nil {
+		return MaintenanceWindow{}, microerror.Mask(err)
+	}
+	end, err := parseTimeOfDay(s, to)
+	if err != nil {
+		return MaintenanceWindow{}, microerror.Mask(err)
+	}
+
+	return MaintenanceWindow{Description: s, Days: days, Start: start, End: end}, nil
+}
+
+func parseTimeOfDay(window string, s string) (time.Duration, error) {
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, microerror.Maskf(parsingFailedError, "annotation %s window %q time %q must be of the form `HH:MM`", AnnotationMaintenanceWindows, window, s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func parseFreezePeriod(s string) (FreezePeriod, error) {
+	from, to, ok := strings.Cut(s, "/")
+	if !ok {
+		return FreezePeriod{}, microerror.Maskf(parsingFailedError, "annotation %s freeze period %q must be of the form `start/end`", AnnotationMaintenanceFreezes, s)
+	}
+
+	layout := "2006-01-02"
+	recurring := false
+	if len(strings.TrimSpace(from)) == len("01-02") {
+		layout = "01-02"
+		recurring = true
+	}
+	start, err := time.Parse(layout, strings.TrimSpace(from))
+	if err != nil {
+		return FreezePeriod{}, microerror.Maskf(parsingFailedError, "annotation %s freeze period %q start is not a date of the form `%s`", AnnotationMaintenanceFreezes, s, layout)
+	}
+	end, err := time.Parse(layout, strings.TrimSpace(to))
+	if err != nil {
+		return FreezePeriod{}, microerror.Maskf(parsingFailedError, "annotation %s freeze period %q end is not a date of the form `%s`", AnnotationMaintenanceFreezes, s, layout)
+	}
+	if !recurring && end.Before(start) {
+		return FreezePeriod{}, microerror.Maskf(parsingFailedError, "annotation %s freeze period %q ends before it starts", AnnotationMaintenanceFreezes, s)
+	}
+
+	return FreezePeriod{Description: s, Recurring: recurring, Start: start, End: end}, nil
+}
+
+// Contains reports whether the time is within the window.
+func (w MaintenanceWindow) Contains(t time.Time) bool {
+	t = t.UTC()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := t.Sub(midnight)
+
+	if w.Start < w.End {
+		return w.Days[t.Weekday()] && offset >= w.Start && offset < w.End
+	}
+	// The window spans midnight, it starts on one of the days and ends on the
+	// following day.
+	if w.Days[t.Weekday()] && offset >= w.Start {
+		return true
+	}
+	return w.Days[(t.Weekday()+6)%7] && offset < w.End
+}
+
+// Contains reports whether the time is within the freeze period.
+func (f FreezePeriod) Contains(t time.Time) bool {
+	t = t.UTC()
+	if !f.Recurring {
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return !day.Before(f.Start) && !day.After(f.End)
+	}
+
+	day := time.Date(0, t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	start := time.Date(0, f.Start.Month(), f.Start.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(0, f.End.Month(), f.End.Day(), 0, 0, 0, 0, time.UTC)
+	if !end.Before(start) {
+		return !day.Before(start) && !day.After(end)
+	}
+	// The period spans the turn of the year.
+	return !day.Before(start) || !day.After(end)
+}
+
+// Allows returns a notAllowedError if the time is within a freeze period or
+// outside of all maintenance windows.
+func (p MaintenancePolicy) Allows(t time.Time) error {
+	for _, freeze := range p.Freezes {
+		if freeze.Contains(t) {
+			return microerror.Maskf(notAllowedError, "%s is within the maintenance freeze period %s.", t.UTC().Format(time.RFC822), freeze.Description)
+		}
+	}
+	if len(p.Windows) == 0 {
+		return nil
+	}
+	var windows []string
+	for _, window := range p.Windows {
+		if window.Contains(t) {
+			return nil
+		}
+		windows = append(windows, window.Description)
+	}
+	return microerror.Maskf(notAllowedError, "%s is outside of the maintenance windows %s (UTC).", t.UTC().Format(time.RFC822), strings.Join(windows, "; "))
+}
+
+// FetchMaintenancePolicy returns the maintenance policy of the cluster. The
+// windows and upgrade spacing of the cluster replace those of its
+// Organization, freeze periods of both apply.
+func FetchMaintenancePolicy(m *Handler, cluster *capi.Cluster) (MaintenancePolicy, error) {
+	policy, err := ParseMaintenancePolicy(cluster.GetAnnotations())
+	if err != nil {
+		return MaintenancePolicy{}, microerror.Maskf(notAllowedError, "Cluster %s: %v", cluster.GetName(), err)
+	}
+
+	organizationName := key.Organization(cluster)
+	if organizationName == "" {
+		return policy, nil
+	}
+	organization := &securityv1alpha1.Organization{}
+	err = m.K8sClient.CtrlClient().Get(context.Background(), client.ObjectKey{Name: normalize.AsDNSLabelName(organizationName)}, organization)
+	if apierrors.IsNotFound(err) {
+		return policy, nil
+	} else if err != nil {
+		return MaintenancePolicy{}, microerror.Mask(err)
+	}
+
+	organizationPolicy, err := ParseMaintenancePolicy(organization.GetAnnotations())
+	if err != nil {
+		// The Organization can not be fixed by this request, so only log.
+		m.Logger.Log("level", "debug", "message", fmt.Sprintf("Ignoring maintenance policy of Organization %s: %v", organization.GetName(), err))
+		return policy, nil
+	}
+	if len(policy.Windows) == 0 {
+		policy.Windows = organizationPolicy.Windows
+	}
+	if policy.UpgradeSpacing == nil {
+		policy.UpgradeSpacing = organizationPolicy.UpgradeSpacing
+	}
+	policy.Freezes = append(policy.Freezes, organizationPolicy.Freezes...)
+
+	return policy, nil
+}
+
+// ValidateScheduledUpgradeSpacing returns a notAllowedError if another cluster
+// in the same namespace has an upgrade scheduled less than spacing apart from
+// the given time.
+func ValidateScheduledUpgradeSpacing(m *Handler, cluster *capi.Cluster, t time.Time, spacing time.Duration) error {
+	var clusters capi.ClusterList
+	err := m.K8sClient.CtrlClient().List(context.Background(), &clusters, client.InNamespace(cluster.GetNamespace()))
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	for _, other := range clusters.Items {
+		if other.GetName() == cluster.GetName() || other.GetDeletionTimestamp() != nil {
+			continue
+		}
+		value, ok := other.GetAnnotations()[annotation.UpdateScheduleTargetTime]
+		if !ok {
+			continue
+		}
+		otherTime, err := time.Parse(time.RFC822, value)
+		if err != nil {
+			continue
+		}
+		distance := t.Sub(otherTime)
+		if distance < 0 {
+			distance = -distance
+		}
+		if distance < spacing {
+			return microerror.Maskf(notAllowedError, "Cluster %s has an upgrade scheduled at %s. Scheduled upgrades in namespace %s must be at least %s apart.",
+				other.GetName(), value, cluster.GetNamespace(), spacing)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package v1alpha3
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMaintenancePolicyAllows(t *testing.T) {
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		time        string
+
+		validPolicy bool
+		allowed     bool
+	}{
+		{
+			// No policy
+			name:        "case 0",
+			time:        "2031-01-04T03:00:00Z",
+			validPolicy: true,
+			allowed:     true,
+		},
+		{
+			// Within a weekday window
+			name:        "case 1",
+			annotations: map[string]string{AnnotationMaintenanceWindows: "Mon-Fri 02:00-06:00"},
+			time:        "2031-01-06T03:00:00Z",
+			validPolicy: true,
+			allowed:     true,
+		},
+		{
+			// Outside of a weekday window
+			name:        "case 2",
+			annotations: map[string]string{AnnotationMaintenanceWindows: "Mon-Fri 02:00-06:00"},
+			time:        "2031-01-04T03:00:00Z",
+			validPolicy: true,
+			allowed:     false,
+		},
+		{
+			// Window spanning midnight, on the following day
+			name:        "case 3",
+			annotations: map[string]string{AnnotationMaintenanceWindows: "Mon-Fri 08:00-10:00; Sat 22:00-04:00"},
+			time:        "2031-01-05T03:00:00Z",
+			validPolicy: true,
+			allowed:     true,
+		},
+		{
+			// Within a one-off freeze period
+			name:        "case 4",
+			annotations: map[string]string{AnnotationMaintenanceFreezes: "2031-01-01/2031-01-06"},
+			time:        "2031-01-06T03:00:00Z",
+			validPolicy: true,
+			allowed:     false,
+		},
+		{
+			// Within a recurring freeze period spanning the turn of the year
+			name: "case 5",
+			annotations: map[string]string{
+				AnnotationMaintenanceWindows: "* 00:00-24:00",
+				AnnotationMaintenanceFreezes: "12-20/01-06",
+			},
+			time:        "2030-12-24T03:00:00Z",
+			validPolicy: true,
+			allowed:     false,
+		},
+		{
+			// Outside of a recurring freeze period
+			name:        "case 6",
+			annotations: map[string]string{AnnotationMaintenanceFreezes: "12-20/01-06"},
+			time:        "2031-03-01T03:00:00Z",
+			validPolicy: true,
+			allowed:     true,
+		},
+		{
+			// Invalid window
+			name:        "case 7",
+			annotations: map[string]string{AnnotationMaintenanceWindows: "Monday 02:00-06:00"},
+			validPolicy: false,
+		},
+		{
+			// Invalid freeze period
+			name:        "case 8",
+			annotations: map[string]string{AnnotationMaintenanceFreezes: "2031-01-06/2031-01-01"},
+			validPolicy: false,
+		},
+		{
+			// Invalid upgrade spacing
+			name:        "case 9",
+			annotations: map[string]string{AnnotationMaintenanceUpgradeSpacing: "-1h"},
+			validPolicy: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			policy, err := ParseMaintenancePolicy(tc.annotations)
+			if !tc.validPolicy {
+				if !IsParsingFailed(err) {
+					t.Fatalf("expected parsing failed error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+
+			tm, err := time.Parse(time.RFC3339, tc.time)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = policy.Allows(tm)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && !IsNotAllowed(err) {
+				t.Fatalf("expected not allowed error but got %v", err)
+			}
+		})
+	}
+}
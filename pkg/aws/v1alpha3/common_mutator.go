@@ -10,6 +10,7 @@ import (
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/key"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/mutator"
 )
 
@@ -108,3 +109,105 @@ func MutateCAPILabel(m *Handler, meta metav1.Object) []mutator.PatchOperation {
 
 	return result
 }
+
+// OperatorVersionLabels are the operator version labels and the release
+// components they are taken from.
+func OperatorVersionLabels() [][2]string {
+	return [][2]string{
+		{label.AWSOperatorVersion, "aws-operator"},
+		{label.ClusterOperatorVersion, "cluster-operator"},
+	}
+}
+
+// MutateReleasePropagation updates the release label of a CR belonging to a
+// cluster to the release of its Cluster CR, together with the operator version
+// labels the CR carries. The operator versions are taken from the Release CR
+// so that they do not depend on the order in which the CRs of the cluster are
+// admitted. Only the admitted object is patched.
+func MutateReleasePropagation(m *Handler, meta metav1.Object) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	if key.Cluster(meta) == "" {
+		return result, nil
+	}
+	cluster, err := FetchCluster(m, meta)
+	if IsNotFound(err) {
+		// The Cluster might be deleted before its children.
+		return result, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if key.Release(cluster) == "" || key.Release(cluster) == key.Release(meta) {
+		return result, nil
+	}
+
+	releaseVersion, err := ReleaseVersion(cluster, result)
+	if err != nil {
+		return nil, microerror.Maskf(parsingFailedError, "unable to parse release version from Cluster")
+	}
+	release, err := FetchRelease(m, releaseVersion)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	m.Logger.Log("level", "debug", "message", fmt.Sprintf("Label %s will be updated from %s to %s of Cluster %s.",
+		label.Release,
+		key.Release(meta),
+		key.Release(cluster),
+		cluster.GetName()))
+	patch := mutator.PatchAdd(fmt.Sprintf("/metadata/labels/%s", EscapeJSONPatchString(label.Release)), key.Release(cluster))
+	result = append(result, patch)
+
+	for _, operator := range OperatorVersionLabels() {
+		if meta.GetLabels()[operator[0]] == "" {
+			continue
+		}
+		patches, err := MutateLabelFromRelease(m, meta, *release, operator[0], operator[1])
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		result = append(result, patches...)
+	}
+
+	return result, nil
+}
+
+// MutateOrganizationLabel defaults the organization label if it is not set.
+// CRs belonging to a cluster take the organization of their Cluster CR.
+// Otherwise the organization is the Organization CR owning the namespace of
+// the object. If neither is found the label is left to the validation.
+func MutateOrganizationLabel(m *Handler, meta metav1.Object) ([]mutator.PatchOperation, error) {
+	var result []mutator.PatchOperation
+
+	if key.Organization(meta) != "" {
+		return result, nil
+	}
+
+	if key.Cluster(meta) != "" {
+		cluster, err := FetchCluster(m, meta)
+		if IsNotFound(err) {
+			// The order of CR creation can vary, so the Cluster might not exist yet.
+			m.Logger.Log("level", "debug", "message", fmt.Sprintf("No Cluster %s could be found: %v", key.Cluster(meta), err))
+		} else if err != nil {
+			return nil, microerror.Mask(err)
+		} else if key.Organization(cluster) != "" {
+			return MutateLabelFromCluster(m, meta, *cluster, label.Organization)
+		}
+	}
+
+	organization, err := FetchNamespaceOrganization(m, meta.GetNamespace())
+	if IsNotFound(err) {
+		m.Logger.Log("level", "debug", "message", fmt.Sprintf("Label %s can not be defaulted: %v", label.Organization, err))
+		return result, nil
+	} else if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	m.Logger.Log("level", "debug", "message", fmt.Sprintf("Label %s is not set and will be defaulted to %s from the Organization of namespace %s.",
+		label.Organization,
+		organization.GetName(),
+		meta.GetNamespace()))
+	patch := mutator.PatchAdd(fmt.Sprintf("/metadata/labels/%s", EscapeJSONPatchString(label.Organization)), organization.GetName())
+	result = append(result, patch)
+
+	return result, nil
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/mutator"
@@ -186,3 +187,259 @@ func TestLabelFromRelease(t *testing.T) {
 		})
 	}
 }
+
+func TestReleasePropagation(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		clusterID       string
+		clusterRelease  string
+		currentOperator string
+		apiUnavailable  bool
+
+		expectedRelease  string
+		expectedOperator string
+		expectedErr      bool
+	}{
+		{
+			// Don't propagate if the release matches the Cluster
+			name: "case 0",
+			ctx:  context.Background(),
+
+			clusterID:       unittest.DefaultClusterID,
+			clusterRelease:  unittest.DefaultReleaseVersion,
+			currentOperator: "1.0.0",
+
+			expectedRelease:  "",
+			expectedOperator: "",
+		},
+		{
+			// Propagate the release and the operator version of the upgraded Cluster
+			name: "case 1",
+			ctx:  context.Background(),
+
+			clusterID:       unittest.DefaultClusterID,
+			clusterRelease:  "100.1.0",
+			currentOperator: "1.0.0",
+
+			expectedRelease:  "100.1.0",
+			expectedOperator: unittest.DefaultAWSOperatorVersion,
+		},
+		{
+			// Don't add an operator label the resource does not carry
+			name: "case 2",
+			ctx:  context.Background(),
+
+			clusterID:       unittest.DefaultClusterID,
+			clusterRelease:  "100.1.0",
+			currentOperator: "",
+
+			expectedRelease:  "100.1.0",
+			expectedOperator: "",
+		},
+		{
+			// Don't propagate if the Cluster does not exist
+			name: "case 3",
+			ctx:  context.Background(),
+
+			clusterID:       "other",
+			clusterRelease:  "100.1.0",
+			currentOperator: "1.0.0",
+
+			expectedRelease:  "",
+			expectedOperator: "",
+		},
+		{
+			// Fail if it can not be told whether the Cluster exists
+			name: "case 4",
+			ctx:  context.Background(),
+
+			clusterID:       unittest.DefaultClusterID,
+			clusterRelease:  "100.1.0",
+			currentOperator: "1.0.0",
+			apiUnavailable:  true,
+
+			expectedErr: true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+			var updatedRelease string
+			var updatedOperator string
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			mutate := &Handler{
+				K8sClient: fakeK8sClient,
+				Logger:    microloggertest.New(),
+			}
+			cluster := unittest.DefaultCluster()
+			cluster.Labels[label.Release] = tc.clusterRelease
+			err = fakeK8sClient.CtrlClient().Create(tc.ctx, cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			release := unittest.NamedRelease("v" + tc.clusterRelease)
+			err = fakeK8sClient.CtrlClient().Create(tc.ctx, &release)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.apiUnavailable {
+				mutate.K8sClient = unittest.FailingK8sClient()
+			}
+
+			// run mutate function to propagate the release of the Cluster to the AWSMachineDeployment
+			var patch []mutator.PatchOperation
+			awsmachinedeployment := unittest.DefaultAWSMachineDeployment()
+			awsmachinedeployment.SetLabels(map[string]string{label.Cluster: tc.clusterID, label.Release: unittest.DefaultReleaseVersion})
+			if tc.currentOperator != "" {
+				awsmachinedeployment.Labels[label.AWSOperatorVersion] = tc.currentOperator
+			}
+			patch, err = MutateReleasePropagation(mutate, awsmachinedeployment.GetObjectMeta())
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatal("expected an error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// parse patches
+			for _, p := range patch {
+				if p.Path == fmt.Sprintf("/metadata/labels/%s", EscapeJSONPatchString(label.Release)) {
+					updatedRelease = p.Value.(string)
+				}
+				if p.Path == fmt.Sprintf("/metadata/labels/%s", EscapeJSONPatchString(label.AWSOperatorVersion)) {
+					updatedOperator = p.Value.(string)
+				}
+			}
+			// check if the labels are as expected
+			if tc.expectedRelease != updatedRelease {
+				t.Fatalf("expected %#q to be equal to %#q", tc.expectedRelease, updatedRelease)
+			}
+			if tc.expectedOperator != updatedOperator {
+				t.Fatalf("expected %#q to be equal to %#q", tc.expectedOperator, updatedOperator)
+			}
+		})
+	}
+}
+
+func TestOrganizationLabel(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		clusterID           string
+		currentOrganization string
+		ownedNamespace      string
+		apiUnavailable      bool
+		expectedPatch       string
+		expectedErr         bool
+	}{
+		{
+			// Don't default the Organization Label if it is set
+			name: "case 0",
+			ctx:  context.Background(),
+
+			clusterID:           unittest.DefaultClusterID,
+			currentOrganization: "other",
+			ownedNamespace:      metav1.NamespaceDefault,
+			expectedPatch:       "",
+		},
+		{
+			// Default the Organization Label from the Cluster
+			name: "case 1",
+			ctx:  context.Background(),
+
+			clusterID:           unittest.DefaultClusterID,
+			currentOrganization: "",
+			ownedNamespace:      "org-other",
+			expectedPatch:       "example-organization",
+		},
+		{
+			// Default the Organization Label from the Organization of the namespace
+			name: "case 2",
+			ctx:  context.Background(),
+
+			clusterID:           "other",
+			currentOrganization: "",
+			ownedNamespace:      metav1.NamespaceDefault,
+			expectedPatch:       unittest.DefaultOrganization().GetName(),
+		},
+		{
+			// Don't default the Organization Label if no source is found
+			name: "case 3",
+			ctx:  context.Background(),
+
+			clusterID:           "other",
+			currentOrganization: "",
+			ownedNamespace:      "org-other",
+			expectedPatch:       "",
+		},
+		{
+			// Fail if it can not be told whether the Cluster exists
+			name: "case 4",
+			ctx:  context.Background(),
+
+			clusterID:           unittest.DefaultClusterID,
+			currentOrganization: "",
+			ownedNamespace:      metav1.NamespaceDefault,
+			apiUnavailable:      true,
+			expectedErr:         true,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+			var updatedOrganization string
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			mutate := &Handler{
+				K8sClient: fakeK8sClient,
+				Logger:    microloggertest.New(),
+			}
+			err = fakeK8sClient.CtrlClient().Create(tc.ctx, unittest.DefaultCluster())
+			if err != nil {
+				t.Fatal(err)
+			}
+			organization := unittest.DefaultOrganization()
+			organization.Status.Namespace = tc.ownedNamespace
+			err = fakeK8sClient.CtrlClient().Create(tc.ctx, organization)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.apiUnavailable {
+				mutate.K8sClient = unittest.FailingK8sClient()
+			}
+
+			// run mutate function to default AWSMachineDeployment organization label
+			var patch []mutator.PatchOperation
+			awsmachinedeployment := unittest.DefaultAWSMachineDeployment()
+			awsmachinedeployment.SetLabels(map[string]string{label.Cluster: tc.clusterID, label.Organization: tc.currentOrganization})
+			patch, err = MutateOrganizationLabel(mutate, awsmachinedeployment.GetObjectMeta())
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatal("expected an error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// parse patches
+			for _, p := range patch {
+				if p.Path == fmt.Sprintf("/metadata/labels/%s", EscapeJSONPatchString(label.Organization)) {
+					updatedOrganization = p.Value.(string)
+				}
+			}
+			// check if the organization label is as expected
+			if tc.expectedPatch != updatedOrganization {
+				t.Fatalf("expected %#q to be equal to %#q", tc.expectedPatch, updatedOrganization)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package v1alpha3
+
+import (
+	"context"
+
+	"github.com/giantswarm/microerror"
+	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/internal/normalize"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/key"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/mutator"
+)
+
+// ValidateNamespacePlacement validates that a CR of a cluster is in the
+// namespace of its Cluster CR and, from the first org-namespace release, of
+// its organization. Since the namespace can not change, updates are only
+// validated when the cluster or organization label changes, so that CRs
+// placed before these rules can still be updated.
+func ValidateNamespacePlacement(m *Handler, kind string, oldObj metav1.Object, obj metav1.Object) error {
+	if oldObj != nil && key.Cluster(oldObj) == key.Cluster(obj) && key.Organization(oldObj) == key.Organization(obj) {
+		return nil
+	}
+
+	versionObj := oldObj
+	if versionObj == nil {
+		versionObj = obj
+	}
+	releaseVersion, err := ReleaseVersion(versionObj, []mutator.PatchOperation{})
+	if err != nil {
+		return microerror.Maskf(parsingFailedError, "unable to parse release version from object")
+	}
+
+	if IsOrgNamespaceVersion(releaseVersion) {
+		err = validateOrgNamespaceName(obj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		err = ValidateOrgNamespaceOwner(m, obj)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+
+	err = ValidateClusterNamespace(m, kind, obj)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+
+	return nil
+}
+
+// ValidateOrgNamespaceOwner returns an error if the namespace of the object
+// does not exist or is not the namespace of the Organization CR named in the
+// organization label.
+func ValidateOrgNamespaceOwner(m *Handler, meta metav1.Object) error {
+	organizationName := key.Organization(meta)
+	if organizationName == "" {
+		return microerror.Maskf(organizationLabelNotFoundError, "Object %s Organization label %#q is empty.", meta.GetName(), label.Organization)
+	}
+
+	var namespace corev1.Namespace
+	err := m.K8sClient.CtrlClient().Get(context.Background(), client.ObjectKey{Name: meta.GetNamespace()}, &namespace)
+	if apierrors.IsNotFound(err) {
+		return microerror.Maskf(notAllowedError, "Namespace %s of object %s does not exist.",
+			meta.GetNamespace(),
+			meta.GetName())
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	var organization securityv1alpha1.Organization
+	err = m.K8sClient.CtrlClient().Get(context.Background(), client.ObjectKey{Name: normalize.AsDNSLabelName(organizationName)}, &organization)
+	if apierrors.IsNotFound(err) {
+		return microerror.Maskf(organizationNotFoundError, "Organization label %#q must contain an existing organization, got %#q but didn't find any CR with name %#q", label.Organization, organizationName, normalize.AsDNSLabelName(organizationName))
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+
+	if organization.Status.Namespace != namespace.GetName() {
+		return microerror.Maskf(notAllowedError, "Namespace %s of object %s is not owned by Organization %s, which owns namespace %#q.",
+			namespace.GetName(),
+			meta.GetName(),
+			organization.GetName(),
+			organization.Status.Namespace)
+	}
+
+	return nil
+}
+
+// ValidateClusterNamespace returns an error if the Cluster CR the object
+// belongs to is in another namespace. A missing Cluster CR is not an error
+// because the order of CR creation can vary.
+func ValidateClusterNamespace(m *Handler, kind string, meta metav1.Object) error {
+	if kind == "Cluster" {
+		return nil
+	}
+	clusterID := key.Cluster(meta)
+	if clusterID == "" {
+		return nil
+	}
+
+	var clusters capi.ClusterList
+	err := m.K8sClient.CtrlClient().List(context.Background(), &clusters, client.MatchingLabels{label.Cluster: clusterID})
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	for _, cluster := range clusters.Items {
+		if cluster.GetNamespace() != meta.GetNamespace() {
+			return microerror.Maskf(notAllowedError, "%s %s/%s must be in namespace %s of its Cluster %s.",
+				kind,
+				meta.GetNamespace(),
+				meta.GetName(),
+				cluster.GetNamespace(),
+				cluster.GetName())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package v1alpha3
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
+	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
+)
+
+func TestValidateNamespacePlacement(t *testing.T) {
+	orgNamespace := "org-example-organization"
+
+	testCases := []struct {
+		name             string
+		namespace        string
+		oldRelease       string
+		oldOrganization  string
+		namespaceExists  bool
+		ownedNamespace   string
+		clusterNamespace string
+
+		valid bool
+	}{
+		{
+			// Valid org namespace shared with the Cluster
+			name:             "case 0",
+			namespace:        orgNamespace,
+			namespaceExists:  true,
+			ownedNamespace:   orgNamespace,
+			clusterNamespace: orgNamespace,
+			valid:            true,
+		},
+		{
+			// Org namespace does not exist
+			name:             "case 1",
+			namespace:        orgNamespace,
+			namespaceExists:  false,
+			ownedNamespace:   orgNamespace,
+			clusterNamespace: orgNamespace,
+			valid:            false,
+		},
+		{
+			// Org namespace is not owned by the Organization
+			name:             "case 2",
+			namespace:        orgNamespace,
+			namespaceExists:  true,
+			ownedNamespace:   "org-other",
+			clusterNamespace: orgNamespace,
+			valid:            false,
+		},
+		{
+			// Cluster is in another namespace
+			name:             "case 3",
+			namespace:        orgNamespace,
+			namespaceExists:  true,
+			ownedNamespace:   orgNamespace,
+			clusterNamespace: metav1.NamespaceDefault,
+			valid:            false,
+		},
+		{
+			// Default namespace in an org-namespace release
+			name:             "case 4",
+			namespace:        metav1.NamespaceDefault,
+			namespaceExists:  true,
+			ownedNamespace:   orgNamespace,
+			clusterNamespace: metav1.NamespaceDefault,
+			valid:            false,
+		},
+		{
+			// Default namespace of a resource created before the upgrade
+			name:             "case 5",
+			namespace:        metav1.NamespaceDefault,
+			oldRelease:       "14.0.0",
+			namespaceExists:  true,
+			ownedNamespace:   orgNamespace,
+			clusterNamespace: metav1.NamespaceDefault,
+			valid:            true,
+		},
+		{
+			// Update of a legacy resource in the default namespace which was
+			// upgraded to an org-namespace release before
+			name:             "case 6",
+			namespace:        metav1.NamespaceDefault,
+			oldRelease:       "18.0.0",
+			namespaceExists:  true,
+			ownedNamespace:   orgNamespace,
+			clusterNamespace: metav1.NamespaceDefault,
+			valid:            true,
+		},
+		{
+			// Organization change of a legacy resource in the default namespace
+			name:             "case 7",
+			namespace:        metav1.NamespaceDefault,
+			oldRelease:       "18.0.0",
+			oldOrganization:  "other",
+			namespaceExists:  true,
+			ownedNamespace:   orgNamespace,
+			clusterNamespace: metav1.NamespaceDefault,
+			valid:            false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var err error
+
+			fakeK8sClient := unittest.FakeK8sClient()
+			handler := &Handler{
+				K8sClient: fakeK8sClient,
+				Logger:    microloggertest.New(),
+			}
+
+			if tc.namespaceExists {
+				namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: tc.namespace}}
+				err = fakeK8sClient.CtrlClient().Create(context.Background(), namespace)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			organization := unittest.DefaultOrganization()
+			organization.Status.Namespace = tc.ownedNamespace
+			err = fakeK8sClient.CtrlClient().Create(context.Background(), organization)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cluster := unittest.DefaultCluster()
+			cluster.SetNamespace(tc.clusterNamespace)
+			err = fakeK8sClient.CtrlClient().Create(context.Background(), cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			awsMachineDeployment := unittest.DefaultAWSMachineDeployment()
+			awsMachineDeployment.SetNamespace(tc.namespace)
+			awsMachineDeployment.Labels[label.Organization] = organization.GetName()
+			awsMachineDeployment.Labels[label.Release] = "18.0.0"
+			var oldAWSMachineDeployment metav1.Object
+			if tc.oldRelease != "" {
+				old := awsMachineDeployment.DeepCopy()
+				old.Labels[label.Release] = tc.oldRelease
+				if tc.oldOrganization != "" {
+					old.Labels[label.Organization] = tc.oldOrganization
+				}
+				oldAWSMachineDeployment = old
+			}
+
+			err = ValidateNamespacePlacement(handler, "AWSMachineDeployment", oldAWSMachineDeployment, awsMachineDeployment)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but returned %v", err)
+			}
+		})
+	}
+}
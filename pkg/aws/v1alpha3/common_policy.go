@@ -0,0 +1,118 @@
+package v1alpha3
+
+import (
+	"os"
+
+	"github.com/giantswarm/microerror"
+	"sigs.k8s.io/yaml"
+)
+
+// Policy bundles the policies of the admission controller which are
+// configured in the policy file.
+type Policy struct {
+	GitopsGuard          GitopsGuard
+	Immutability         ImmutabilityPolicy
+	InstanceTypes        InstanceTypePolicy
+	ReleaseGraph         ReleaseGraph
+	RestrictedIdentities RestrictedIdentities
+	Scaling              ScalingPolicy
+}
+
+// policyFile is the content of the policy file. Sections which are not set
+// keep their defaults.
+type policyFile struct {
+	GitopsGuard          *GitopsGuard          `json:"gitopsGuard,omitempty"`
+	Immutability         *ImmutabilityPolicy   `json:"immutability,omitempty"`
+	InstanceTypes        *InstanceTypePolicy   `json:"instanceTypes,omitempty"`
+	ReleaseGraph         *ReleaseGraph         `json:"releaseGraph,omitempty"`
+	RestrictedIdentities *RestrictedIdentities `json:"restrictedIdentities,omitempty"`
+	Scaling              *ScalingPolicy        `json:"scaling,omitempty"`
+}
+
+// DefaultPolicy returns the default of each section of the policy.
+func DefaultPolicy(adminGroup string) Policy {
+	return Policy{
+		GitopsGuard:          DefaultGitopsGuard(),
+		Immutability:         DefaultImmutabilityPolicy(),
+		RestrictedIdentities: DefaultRestrictedIdentities(adminGroup),
+	}
+}
+
+// LoadPolicy reads the Policy from a YAML or JSON file. An empty path returns
+// DefaultPolicy.
+func LoadPolicy(path string, adminGroup string) (Policy, error) {
+	if path == "" {
+		return DefaultPolicy(adminGroup), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, microerror.Maskf(invalidConfigError, "unable to read policy %s: %v", path, err)
+	}
+	policy, err := ParsePolicy(data, adminGroup)
+	if err != nil {
+		return Policy{}, microerror.Mask(err)
+	}
+
+	return policy, nil
+}
+
+// ParsePolicy reads the Policy from YAML or JSON. Sections which are not set
+// keep the defaults of DefaultPolicy, and so do the capabilities of the
+// restricted identities.
+func ParsePolicy(data []byte, adminGroup string) (Policy, error) {
+	var file policyFile
+	err := yaml.UnmarshalStrict(data, &file)
+	if err != nil {
+		return Policy{}, microerror.Maskf(invalidConfigError, "unable to parse policy: %v", err)
+	}
+
+	policy := DefaultPolicy(adminGroup)
+	if file.GitopsGuard != nil {
+		policy.GitopsGuard = *file.GitopsGuard
+	}
+	if file.Immutability != nil {
+		policy.Immutability = *file.Immutability
+	}
+	if file.InstanceTypes != nil {
+		policy.InstanceTypes = *file.InstanceTypes
+	}
+	if file.ReleaseGraph != nil {
+		policy.ReleaseGraph = *file.ReleaseGraph
+	}
+	if file.RestrictedIdentities != nil {
+		policy.RestrictedIdentities = file.RestrictedIdentities.withDefaults(policy.RestrictedIdentities)
+	}
+	if file.Scaling != nil {
+		policy.Scaling = *file.Scaling
+	}
+
+	err = policy.Validate()
+	if err != nil {
+		return Policy{}, microerror.Maskf(invalidConfigError, "policy is invalid: %v", err)
+	}
+
+	return policy, nil
+}
+
+// Validate returns an error naming the first invalid section of the policy.
+func (p Policy) Validate() error {
+	sections := []struct {
+		name   string
+		policy interface{ Validate() error }
+	}{
+		{"gitopsGuard", p.GitopsGuard},
+		{"immutability", p.Immutability},
+		{"instanceTypes", p.InstanceTypes},
+		{"releaseGraph", p.ReleaseGraph},
+		{"restrictedIdentities", p.RestrictedIdentities},
+		{"scaling", p.Scaling},
+	}
+	for _, section := range sections {
+		err := section.policy.Validate()
+		if err != nil {
+			return microerror.Maskf(parsingFailedError, "%s: %v", section.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package v1alpha3
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		noFile  bool
+		noPath  bool
+
+		valid        bool
+		gitopsGuard  GitopsGuard
+		immutability ImmutabilityPolicy
+		maxWorkers   int
+	}{
+		{
+			// No policy file
+			name:         "case 0",
+			noPath:       true,
+			valid:        true,
+			gitopsGuard:  DefaultGitopsGuard(),
+			immutability: DefaultImmutabilityPolicy(),
+		},
+		{
+			// Sections which are not set keep their defaults
+			name: "case 1",
+			content: `gitopsGuard:
+  rules:
+  - kinds: [AWSCluster]
+    fields: [spec.provider.region]
+scaling:
+  default:
+    maxWorkers: 100
+`,
+			valid: true,
+			gitopsGuard: GitopsGuard{
+				Rules: []GitopsRule{{Kinds: []string{"AWSCluster"}, Fields: []string{"spec.provider.region"}}},
+			},
+			immutability: DefaultImmutabilityPolicy(),
+			maxWorkers:   100,
+		},
+		{
+			// Empty section
+			name:         "case 2",
+			content:      "gitopsGuard: {}\n",
+			valid:        true,
+			gitopsGuard:  GitopsGuard{},
+			immutability: DefaultImmutabilityPolicy(),
+		},
+		{
+			// Unknown section
+			name:    "case 3",
+			content: "scalingPolicy:\n  default:\n    maxWorkers: 100\n",
+			valid:   false,
+		},
+		{
+			// Invalid section
+			name:    "case 4",
+			content: "scaling:\n  default:\n    maxWorkers: -2\n",
+			valid:   false,
+		},
+		{
+			// Missing policy file
+			name:   "case 5",
+			noFile: true,
+			valid:  false,
+		},
+		{
+			// Invalid first ARM release
+			name:    "case 6",
+			content: "instanceTypes:\n  firstARMRelease: twenty\n",
+			valid:   false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.yaml")
+			if tc.noPath {
+				path = ""
+			} else if !tc.noFile {
+				err := os.WriteFile(path, []byte(tc.content), 0600)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			policy, err := LoadPolicy(path, "customer-admins")
+			if !tc.valid {
+				if !IsInvalidConfig(err) {
+					t.Fatalf("expected invalid config error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(policy.GitopsGuard, tc.gitopsGuard) {
+				t.Fatalf("expected gitops guard %v but got %v", tc.gitopsGuard, policy.GitopsGuard)
+			}
+			if !reflect.DeepEqual(policy.Immutability, tc.immutability) {
+				t.Fatalf("expected immutability policy %v but got %v", tc.immutability, policy.Immutability)
+			}
+			if policy.Scaling.Default.MaxWorkers != tc.maxWorkers {
+				t.Fatalf("expected max workers %d but got %d", tc.maxWorkers, policy.Scaling.Default.MaxWorkers)
+			}
+			if !reflect.DeepEqual(policy.RestrictedIdentities, DefaultRestrictedIdentities("customer-admins")) {
+				t.Fatalf("expected default restricted identities but got %v", policy.RestrictedIdentities)
+			}
+		})
+	}
+}
+
+// policySection returns a policy file which only sets the given section.
+func policySection(section string, content string) []byte {
+	var b strings.Builder
+	b.WriteString(section + ":\n")
+	for _, line := range strings.Split(strings.TrimSuffix(content, "\n"), "\n") {
+		b.WriteString("  " + line + "\n")
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,170 @@
+package v1alpha3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/giantswarm/microerror"
+	releasev1alpha1 "github.com/giantswarm/release-operator/v4/api/v1alpha1"
+)
+
+const (
+	// AnnotationReleaseUpgradeFrom restricts the releases a Release can be
+	// upgraded from. The value is a comma separated list of versions or version
+	// ranges, e.g. `18.4.0, >=19.0.0 <19.2.0`.
+	AnnotationReleaseUpgradeFrom = "release.giantswarm.io/upgrade-from"
+	// AnnotationReleaseMandatory marks a Release which upgrades must not skip
+	// when set to "true".
+	AnnotationReleaseMandatory = "release.giantswarm.io/mandatory"
+	// AnnotationReleaseBlocked marks a Release which clusters must not be
+	// upgraded to. The value is the reason.
+	AnnotationReleaseBlocked = "release.giantswarm.io/blocked"
+)
+
+// ReleaseGraph describes the allowed upgrade paths between releases on top of
+// the major version rules.
+type ReleaseGraph struct {
+	// Edges restrict the releases a target release can be upgraded from.
+	// Targets without edges can be upgraded to from any release.
+	Edges []ReleaseEdge `json:"edges,omitempty"`
+	// Mandatory releases must not be skipped by upgrades.
+	Mandatory []string `json:"mandatory,omitempty"`
+	// Blocked releases must not be upgraded to. The value is the reason.
+	Blocked map[string]string `json:"blocked,omitempty"`
+}
+
+// ReleaseEdge allows upgrades from the releases matching the version range
+// From to the release To.
+type ReleaseEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Validate returns an error if any version or version range of the graph can
+// not be parsed.
+func (g ReleaseGraph) Validate() error {
+	for _, edge := range g.Edges {
+		if _, err := semver.ParseRange(edge.From); err != nil {
+			return microerror.Maskf(parsingFailedError, "edge source %q is not a valid version range: %v", edge.From, err)
+		}
+		if _, err := semver.Parse(releaseVersionString(edge.To)); err != nil {
+			return microerror.Maskf(parsingFailedError, "edge target %q is not a valid version: %v", edge.To, err)
+		}
+	}
+	for _, version := range g.Mandatory {
+		if _, err := semver.Parse(releaseVersionString(version)); err != nil {
+			return microerror.Maskf(parsingFailedError, "mandatory release %q is not a valid version: %v", version, err)
+		}
+	}
+	for version := range g.Blocked {
+		if _, err := semver.Parse(releaseVersionString(version)); err != nil {
+			return microerror.Maskf(parsingFailedError, "blocked release %q is not a valid version: %v", version, err)
+		}
+	}
+	return nil
+}
+
+// FetchReleaseGraph returns the given graph extended by the upgrade path
+// annotations of all Release CRs. Invalid annotations are skipped.
+func FetchReleaseGraph(m *Handler, base ReleaseGraph) (ReleaseGraph, error) {
+	graph := ReleaseGraph{
+		Edges:     append([]ReleaseEdge{}, base.Edges...),
+		Mandatory: append([]string{}, base.Mandatory...),
+		Blocked:   map[string]string{},
+	}
+	for version, reason := range base.Blocked {
+		graph.Blocked[version] = reason
+	}
+
+	var releases releasev1alpha1.ReleaseList
+	err := m.K8sClient.CtrlClient().List(context.Background(), &releases)
+	if err != nil {
+		return ReleaseGraph{}, microerror.Mask(err)
+	}
+
+	for _, release := range releases.Items {
+		version := releaseVersionString(release.GetName())
+		if _, err := semver.Parse(version); err != nil {
+			continue
+		}
+		annotations := release.GetAnnotations()
+
+		if from, ok := annotations[AnnotationReleaseUpgradeFrom]; ok {
+			for _, r := range strings.Split(from, ",") {
+				r = strings.TrimSpace(r)
+				if _, err := semver.ParseRange(r); err != nil {
+					m.Logger.Log("level", "debug", "message", fmt.Sprintf("Ignoring invalid %s annotation %q of Release %s: %v", AnnotationReleaseUpgradeFrom, r, release.GetName(), err))
+					continue
+				}
+				graph.Edges = append(graph.Edges, ReleaseEdge{From: r, To: version})
+			}
+		}
+		if annotations[AnnotationReleaseMandatory] == "true" {
+			graph.Mandatory = append(graph.Mandatory, version)
+		}
+		if reason, ok := annotations[AnnotationReleaseBlocked]; ok {
+			graph.Blocked[version] = reason
+		}
+	}
+
+	return graph, nil
+}
+
+// ValidateUpgrade returns a notAllowedError if the upgrade from one release to
+// another is not allowed by the graph. Downgrades are only checked for blocked
+// targets.
+func (g ReleaseGraph) ValidateUpgrade(from semver.Version, to semver.Version) error {
+	for version, reason := range g.Blocked {
+		blocked, err := semver.Parse(releaseVersionString(version))
+		if err != nil {
+			continue
+		}
+		if to.EQ(blocked) {
+			if reason == "" {
+				return microerror.Maskf(notAllowedError, "Release %s is blocked.", to.String())
+			}
+			return microerror.Maskf(notAllowedError, "Release %s is blocked: %s", to.String(), reason)
+		}
+	}
+
+	if to.LE(from) {
+		return nil
+	}
+
+	for _, version := range g.Mandatory {
+		mandatory, err := semver.Parse(releaseVersionString(version))
+		if err != nil {
+			continue
+		}
+		if mandatory.GT(from) && mandatory.LT(to) {
+			return microerror.Maskf(notAllowedError, "Upgrade from %s to %s skips the mandatory release %s, please upgrade to %s first.", from.String(), to.String(), mandatory.String(), mandatory.String())
+		}
+	}
+
+	var sources []string
+	for _, edge := range g.Edges {
+		target, err := semver.Parse(releaseVersionString(edge.To))
+		if err != nil || !target.EQ(to) {
+			continue
+		}
+		sources = append(sources, edge.From)
+		r, err := semver.ParseRange(edge.From)
+		if err != nil {
+			continue
+		}
+		if r(from) {
+			return nil
+		}
+	}
+	if len(sources) > 0 {
+		return microerror.Maskf(notAllowedError, "Release %s can only be upgraded to from releases matching %q, but the current release is %s.", to.String(), strings.Join(sources, ", "), from.String())
+	}
+
+	return nil
+}
+
+func releaseVersionString(version string) string {
+	return strings.TrimPrefix(strings.TrimSpace(version), "v")
+}
@@ -0,0 +1,186 @@
+package v1alpha3
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/giantswarm/micrologger/microloggertest"
+
+	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
+)
+
+func TestReleaseGraphValidateUpgrade(t *testing.T) {
+	file := ReleaseGraph{
+		Edges: []ReleaseEdge{
+			{From: ">=18.4.0 <19.0.0", To: "19.0.0"},
+		},
+		Blocked: map[string]string{"19.0.1": "broken CNI"},
+	}
+	annotations := map[string]map[string]string{
+		"v18.4.0": {AnnotationReleaseMandatory: "true"},
+		"v19.1.0": {AnnotationReleaseUpgradeFrom: "19.0.0, 18.5.0"},
+		"v19.2.0": {AnnotationReleaseBlocked: ""},
+		"v19.3.0": {AnnotationReleaseUpgradeFrom: "not a range"},
+	}
+
+	testCases := []struct {
+		name string
+		from string
+		to   string
+
+		allowed bool
+	}{
+		{
+			// Upgrade without restrictions
+			name:    "case 0",
+			from:    "18.0.0",
+			to:      "18.3.0",
+			allowed: true,
+		},
+		{
+			// Upgrade skipping a mandatory release
+			name:    "case 1",
+			from:    "18.0.0",
+			to:      "18.5.0",
+			allowed: false,
+		},
+		{
+			// Upgrade to a mandatory release
+			name:    "case 2",
+			from:    "18.0.0",
+			to:      "18.4.0",
+			allowed: true,
+		},
+		{
+			// Upgrade along an edge from the configuration
+			name:    "case 3",
+			from:    "18.4.0",
+			to:      "19.0.0",
+			allowed: true,
+		},
+		{
+			// Upgrade to a target with edges from another source
+			name:    "case 4",
+			from:    "19.0.0",
+			to:      "19.1.0",
+			allowed: true,
+		},
+		{
+			// Upgrade to a target with edges not matching the source
+			name:    "case 5",
+			from:    "18.4.0",
+			to:      "19.1.0",
+			allowed: false,
+		},
+		{
+			// Upgrade to a release blocked in the configuration
+			name:    "case 6",
+			from:    "19.0.0",
+			to:      "19.0.1",
+			allowed: false,
+		},
+		{
+			// Upgrade to a release blocked by annotation
+			name:    "case 7",
+			from:    "19.1.0",
+			to:      "19.2.0",
+			allowed: false,
+		},
+		{
+			// Invalid annotations are ignored
+			name:    "case 8",
+			from:    "19.1.0",
+			to:      "19.3.0",
+			allowed: true,
+		},
+		{
+			// Downgrades are not checked for mandatory releases
+			name:    "case 9",
+			from:    "18.5.0",
+			to:      "18.0.0",
+			allowed: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			handler := &Handler{
+				K8sClient: fakeK8sClient,
+				Logger:    microloggertest.New(),
+			}
+			for name, a := range annotations {
+				release := unittest.NamedRelease(name)
+				release.SetAnnotations(a)
+				err := fakeK8sClient.CtrlClient().Create(context.Background(), &release)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			graph, err := FetchReleaseGraph(handler, file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = graph.ValidateUpgrade(semver.MustParse(tc.from), semver.MustParse(tc.to))
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && !IsNotAllowed(err) {
+				t.Fatalf("expected not allowed error but got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseReleaseGraph(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+
+		valid bool
+	}{
+		{
+			// Valid graph
+			name: "case 0",
+			content: `edges:
+- from: ">=18.4.0 <19.0.0"
+  to: "19.0.0"
+mandatory:
+- "18.4.0"
+blocked:
+  "19.0.1": "broken CNI"
+`,
+			valid: true,
+		},
+		{
+			// Invalid version range
+			name: "case 1",
+			content: `edges:
+- from: "18.x.y"
+  to: "19.0.0"
+`,
+			valid: false,
+		},
+		{
+			// Unknown field
+			name:    "case 2",
+			content: "skipped: []\n",
+			valid:   false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := ParsePolicy(policySection("releaseGraph", tc.content), "")
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error but got %v", err)
+			}
+		})
+	}
+}
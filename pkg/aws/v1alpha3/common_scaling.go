@@ -0,0 +1,87 @@
+package v1alpha3
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+// ScalingUnlimited lifts a limit which is set by the default or the
+// organization.
+const ScalingUnlimited = -1
+
+// ScalingPolicy limits the scaling of the node pools of clusters.
+type ScalingPolicy struct {
+	// Default limits apply to all clusters.
+	Default ScalingLimits `json:"default"`
+	// Organizations override the default limits for the clusters of an
+	// organization, by organization name.
+	Organizations map[string]ScalingLimits `json:"organizations,omitempty"`
+	// Clusters override the organization and default limits for a cluster, by
+	// cluster ID.
+	Clusters map[string]ScalingLimits `json:"clusters,omitempty"`
+}
+
+// ScalingLimits are the node pool scaling limits of a cluster. Zero means
+// unlimited, or inherited when overriding other limits. ScalingUnlimited
+// means unlimited in either case.
+type ScalingLimits struct {
+	// MaxNodesPerNodePool limits the scaling maximum of each node pool.
+	MaxNodesPerNodePool int `json:"maxNodesPerNodePool,omitempty"`
+	// MaxWorkers limits the sum of the scaling maxima of all node pools of
+	// the cluster.
+	MaxWorkers int `json:"maxWorkers,omitempty"`
+	// MaxNodePools limits the number of node pools of the cluster.
+	MaxNodePools int `json:"maxNodePools,omitempty"`
+}
+
+// Validate returns an error if a limit is negative and not ScalingUnlimited.
+func (p ScalingPolicy) Validate() error {
+	err := p.Default.Validate()
+	if err != nil {
+		return microerror.Maskf(parsingFailedError, "default: %v", err)
+	}
+	for name, limits := range p.Organizations {
+		err = limits.Validate()
+		if err != nil {
+			return microerror.Maskf(parsingFailedError, "organization %s: %v", name, err)
+		}
+	}
+	for id, limits := range p.Clusters {
+		err = limits.Validate()
+		if err != nil {
+			return microerror.Maskf(parsingFailedError, "cluster %s: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if a limit is negative and not ScalingUnlimited.
+func (l ScalingLimits) Validate() error {
+	if l.MaxNodesPerNodePool < ScalingUnlimited || l.MaxWorkers < ScalingUnlimited || l.MaxNodePools < ScalingUnlimited {
+		return microerror.Maskf(parsingFailedError, "limits must not be negative, except for %d which means unlimited", ScalingUnlimited)
+	}
+	return nil
+}
+
+// Limits returns the limits of a cluster. Each limit is taken from the
+// cluster, the organization or the default, whichever is set first. Unlimited
+// limits are returned as zero.
+func (p ScalingPolicy) Limits(clusterID string, organization string) ScalingLimits {
+	limits := p.Default
+	for _, override := range []ScalingLimits{p.Organizations[organization], p.Clusters[clusterID]} {
+		if override.MaxNodesPerNodePool != 0 {
+			limits.MaxNodesPerNodePool = override.MaxNodesPerNodePool
+		}
+		if override.MaxWorkers != 0 {
+			limits.MaxWorkers = override.MaxWorkers
+		}
+		if override.MaxNodePools != 0 {
+			limits.MaxNodePools = override.MaxNodePools
+		}
+	}
+	for _, limit := range []*int{&limits.MaxNodesPerNodePool, &limits.MaxWorkers, &limits.MaxNodePools} {
+		if *limit == ScalingUnlimited {
+			*limit = 0
+		}
+	}
+	return limits
+}
@@ -0,0 +1,120 @@
+package v1alpha3
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestScalingPolicyLimits(t *testing.T) {
+	policy := ScalingPolicy{
+		Default: ScalingLimits{MaxNodesPerNodePool: 20, MaxWorkers: 100, MaxNodePools: 10},
+		Organizations: map[string]ScalingLimits{
+			"example-organization": {MaxWorkers: 50},
+		},
+		Clusters: map[string]ScalingLimits{
+			"a1b2c": {MaxNodePools: 3, MaxWorkers: 30},
+			"d4e5f": {MaxNodesPerNodePool: ScalingUnlimited, MaxWorkers: ScalingUnlimited},
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		clusterID    string
+		organization string
+
+		expected ScalingLimits
+	}{
+		{
+			// Default limits
+			name:         "case 0",
+			clusterID:    "x9y8z",
+			organization: "other",
+			expected:     ScalingLimits{MaxNodesPerNodePool: 20, MaxWorkers: 100, MaxNodePools: 10},
+		},
+		{
+			// Organization overrides the default
+			name:         "case 1",
+			clusterID:    "x9y8z",
+			organization: "example-organization",
+			expected:     ScalingLimits{MaxNodesPerNodePool: 20, MaxWorkers: 50, MaxNodePools: 10},
+		},
+		{
+			// Cluster overrides the organization and the default
+			name:         "case 2",
+			clusterID:    "a1b2c",
+			organization: "example-organization",
+			expected:     ScalingLimits{MaxNodesPerNodePool: 20, MaxWorkers: 30, MaxNodePools: 3},
+		},
+		{
+			// Cluster lifts the organization and default limits
+			name:         "case 3",
+			clusterID:    "d4e5f",
+			organization: "example-organization",
+			expected:     ScalingLimits{MaxNodesPerNodePool: 0, MaxWorkers: 0, MaxNodePools: 10},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			limits := policy.Limits(tc.clusterID, tc.organization)
+			if !reflect.DeepEqual(limits, tc.expected) {
+				t.Fatalf("expected %#v to be equal to %#v", limits, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParseScalingPolicy(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+
+		valid bool
+	}{
+		{
+			// Valid policy
+			name: "case 0",
+			content: `default:
+  maxNodesPerNodePool: 50
+organizations:
+  example-organization:
+    maxWorkers: 200
+clusters:
+  a1b2c:
+    maxNodePools: 5
+`,
+			valid: true,
+		},
+		{
+			// Negative limit
+			name:    "case 1",
+			content: "clusters:\n  a1b2c:\n    maxWorkers: -2\n",
+			valid:   false,
+		},
+		{
+			// Unknown limit
+			name:    "case 2",
+			content: "default:\n  maxNodes: 5\n",
+			valid:   false,
+		},
+		{
+			// Unlimited override
+			name:    "case 3",
+			content: "default:\n  maxWorkers: 100\nclusters:\n  a1b2c:\n    maxWorkers: -1\n",
+			valid:   true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			_, err := ParsePolicy(policySection("scaling", tc.content), "")
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && !IsInvalidConfig(err) {
+				t.Fatalf("expected invalid config error but got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package v1alpha3
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/giantswarm/microerror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// providerTagPattern matches the characters AWS allows in tag keys and values.
+var providerTagPattern = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+// ValidateLabelConstraints validates that provider tag labels are valid AWS
+// tags and that the service priority label has a valid priority.
+func ValidateLabelConstraints(kind string, oldObj metav1.Object, obj metav1.Object) error {
+	var oldLabels map[string]string
+	if oldObj != nil {
+		oldLabels = oldObj.GetLabels()
+	}
+
+	var reasons []string
+	var oldTags, tags int
+	for key := range oldLabels {
+		if IsProviderTagLabel(key) {
+			oldTags++
+		}
+	}
+	var keys []string
+	for key := range obj.GetLabels() {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := obj.GetLabels()[key]
+		if IsProviderTagLabel(key) {
+			tags++
+		}
+		if oldValue, ok := oldLabels[key]; ok && oldValue == value {
+			continue
+		}
+		if IsProviderTagLabel(key) {
+			reasons = append(reasons, providerTagViolations(key, value)...)
+		}
+		if IsServicePriorityLabel(key) && !Contains(ValidServicePriorities(), value) {
+			reasons = append(reasons, fmt.Sprintf("label %s has invalid value %#q, valid values are %s",
+				key,
+				value,
+				strings.Join(ValidServicePriorities(), ", ")))
+		}
+	}
+	if tags > MaxProviderTagLabels && tags > oldTags {
+		reasons = append(reasons, fmt.Sprintf("%d provider tag labels exceed the maximum of %d", tags, MaxProviderTagLabels))
+	}
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	return microerror.Maskf(notAllowedError, "Labels of %s %s/%s are invalid: %s.",
+		kind,
+		obj.GetNamespace(),
+		obj.GetName(),
+		strings.Join(reasons, "; "),
+	)
+}
+
+// providerTagViolations returns the AWS tag constraints violated by a
+// provider tag label. The tag key is the name of the label.
+func providerTagViolations(key string, value string) []string {
+	var result []string
+
+	tagKey := key
+	if i := strings.Index(key, "/"); i >= 0 {
+		tagKey = key[i+1:]
+	}
+	if utf8.RuneCountInString(tagKey) == 0 || utf8.RuneCountInString(tagKey) > MaxProviderTagKeyLength {
+		result = append(result, fmt.Sprintf("label %s has a tag key of invalid length, valid lengths are 1 to %d", key, MaxProviderTagKeyLength))
+	}
+	if !providerTagPattern.MatchString(tagKey) {
+		result = append(result, fmt.Sprintf("label %s has a tag key with invalid characters, valid are letters, numbers, spaces and _.:/=+-@", key))
+	}
+	if strings.HasPrefix(strings.ToLower(tagKey), ReservedProviderTagPrefix) {
+		result = append(result, fmt.Sprintf("label %s has a tag key with the prefix %#q reserved by AWS", key, ReservedProviderTagPrefix))
+	}
+	if utf8.RuneCountInString(value) > MaxProviderTagValueLength {
+		result = append(result, fmt.Sprintf("label %s has a tag value longer than %d", key, MaxProviderTagValueLength))
+	}
+	if !providerTagPattern.MatchString(value) {
+		result = append(result, fmt.Sprintf("label %s has a tag value with invalid characters, valid are letters, numbers, spaces and _.:/=+-@", key))
+	}
+
+	return result
+}
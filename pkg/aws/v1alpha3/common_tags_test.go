@@ -0,0 +1,103 @@
+package v1alpha3
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
+	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
+)
+
+func TestValidateLabelConstraints(t *testing.T) {
+	manyTags := map[string]string{}
+	for i := 0; i <= MaxProviderTagLabels; i++ {
+		manyTags[fmt.Sprintf("%s/tag-%d", ProviderTagLabelPart, i)] = "value"
+	}
+
+	testCases := []struct {
+		name      string
+		oldLabels map[string]string
+		labels    map[string]string
+
+		allowed bool
+		message string
+	}{
+		{
+			// Valid provider tag and service priority
+			name:    "case 0",
+			labels:  map[string]string{ProviderTagLabelPart + "/cost-center": "team a/1", label.ServicePriority: "highest"},
+			allowed: true,
+		},
+		{
+			// Invalid service priority
+			name:    "case 1",
+			labels:  map[string]string{label.ServicePriority: "critical"},
+			allowed: false,
+			message: "valid values are highest, medium, lowest",
+		},
+		{
+			// Provider tag key with the reserved AWS prefix
+			name:    "case 2",
+			labels:  map[string]string{ProviderTagLabelPart + "/AWS:owner": "team"},
+			allowed: false,
+			message: "reserved by AWS",
+		},
+		{
+			// Provider tag value with invalid characters
+			name:    "case 3",
+			labels:  map[string]string{ProviderTagLabelPart + "/owner": "team*"},
+			allowed: false,
+			message: "tag value with invalid characters",
+		},
+		{
+			// Provider tag key which is too long
+			name:    "case 4",
+			labels:  map[string]string{ProviderTagLabelPart + "/" + strings.Repeat("a", MaxProviderTagKeyLength+1): "team"},
+			allowed: false,
+			message: "tag key of invalid length",
+		},
+		{
+			// Too many provider tags
+			name:    "case 5",
+			labels:  manyTags,
+			allowed: false,
+			message: "exceed the maximum of 40",
+		},
+		{
+			// Unchanged invalid service priority on update
+			name:      "case 6",
+			oldLabels: map[string]string{label.ServicePriority: "critical"},
+			labels:    map[string]string{label.ServicePriority: "critical", "example-key": "changed"},
+			allowed:   true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.SetLabels(tc.labels)
+
+			var err error
+			if tc.oldLabels != nil {
+				oldAWSCluster := unittest.DefaultAWSCluster()
+				oldAWSCluster.SetLabels(tc.oldLabels)
+				err = ValidateLabelConstraints("AWSCluster", oldAWSCluster, awsCluster)
+			} else {
+				err = ValidateLabelConstraints("AWSCluster", nil, awsCluster)
+			}
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed {
+				if !IsNotAllowed(err) {
+					t.Fatalf("expected not allowed error but got %v", err)
+				}
+				if !strings.Contains(err.Error(), tc.message) {
+					t.Fatalf("expected error to contain %q but got %v", tc.message, err)
+				}
+			}
+		})
+	}
+}
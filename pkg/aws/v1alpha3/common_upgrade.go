@@ -0,0 +1,148 @@
+package v1alpha3
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	infrastructurev1alpha3 "github.com/giantswarm/apiextensions/v6/pkg/apis/infrastructure/v1alpha3"
+	"github.com/giantswarm/k8smetadata/pkg/annotation"
+	"github.com/giantswarm/microerror"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/key"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
+)
+
+// UpgradeBlocker is an object which prevents the upgrade of a cluster.
+type UpgradeBlocker struct {
+	Kind   string
+	Name   string
+	Reason string
+}
+
+func (b UpgradeBlocker) String() string {
+	return fmt.Sprintf("%s %s %s", b.Kind, b.Name, b.Reason)
+}
+
+// UpgradeCheck returns the objects blocking the upgrade of a cluster from the
+// release of oldCluster to the release of newCluster.
+type UpgradeCheck func(m *Handler, oldCluster *capi.Cluster, newCluster *capi.Cluster) ([]UpgradeBlocker, error)
+
+// DefaultUpgradeChecks are the checks run before every cluster upgrade.
+func DefaultUpgradeChecks() []UpgradeCheck {
+	return []UpgradeCheck{
+		CheckClusterNotPaused,
+		CheckNoPendingScheduledUpgrade,
+		CheckControlPlaneHealthy,
+		CheckMachineDeploymentsSettled,
+	}
+}
+
+// ValidateUpgradeReadiness runs all checks and returns a notAllowedError
+// listing every blocking object.
+func ValidateUpgradeReadiness(m *Handler, oldCluster *capi.Cluster, newCluster *capi.Cluster, checks []UpgradeCheck) error {
+	var blockers []string
+	for _, check := range checks {
+		b, err := check(m, oldCluster, newCluster)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		for _, blocker := range b {
+			blockers = append(blockers, blocker.String())
+		}
+	}
+	if len(blockers) > 0 {
+		return microerror.Maskf(notAllowedError, "Cluster %s can not be upgraded at the present moment: %s.",
+			newCluster.GetName(),
+			strings.Join(blockers, "; "),
+		)
+	}
+	return nil
+}
+
+// CheckClusterNotPaused blocks upgrades of paused clusters, since their
+// controllers would not roll out the new release.
+func CheckClusterNotPaused(m *Handler, oldCluster *capi.Cluster, newCluster *capi.Cluster) ([]UpgradeBlocker, error) {
+	if newCluster.Spec.Paused {
+		return []UpgradeBlocker{{Kind: "Cluster", Name: newCluster.GetName(), Reason: "is paused"}}, nil
+	}
+	if _, ok := newCluster.GetAnnotations()[capi.PausedAnnotation]; ok {
+		return []UpgradeBlocker{{Kind: "Cluster", Name: newCluster.GetName(), Reason: fmt.Sprintf("is paused by annotation %s", capi.PausedAnnotation)}}, nil
+	}
+	return nil, nil
+}
+
+// CheckNoPendingScheduledUpgrade blocks upgrades to a release other than the
+// one of a pending scheduled upgrade.
+func CheckNoPendingScheduledUpgrade(m *Handler, oldCluster *capi.Cluster, newCluster *capi.Cluster) ([]UpgradeBlocker, error) {
+	targetRelease, ok := newCluster.GetAnnotations()[annotation.UpdateScheduleTargetRelease]
+	if !ok || targetRelease == key.Release(newCluster) {
+		return nil, nil
+	}
+	return []UpgradeBlocker{{
+		Kind:   "Cluster",
+		Name:   newCluster.GetName(),
+		Reason: fmt.Sprintf("has a pending upgrade to release %s scheduled at %s", targetRelease, newCluster.GetAnnotations()[annotation.UpdateScheduleTargetTime]),
+	}}, nil
+}
+
+// CheckControlPlaneHealthy blocks upgrades while not all control plane
+// replicas are ready.
+func CheckControlPlaneHealthy(m *Handler, oldCluster *capi.Cluster, newCluster *capi.Cluster) ([]UpgradeBlocker, error) {
+	var controlPlanes infrastructurev1alpha3.G8sControlPlaneList
+	err := m.K8sClient.CtrlClient().List(context.Background(), &controlPlanes, clusterSelector(newCluster)...)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var blockers []UpgradeBlocker
+	for _, controlPlane := range controlPlanes.Items {
+		if controlPlane.Status.ReadyReplicas < int32(controlPlane.Spec.Replicas) {
+			blockers = append(blockers, UpgradeBlocker{
+				Kind:   "G8sControlPlane",
+				Name:   controlPlane.GetName(),
+				Reason: fmt.Sprintf("has %d of %d replicas ready", controlPlane.Status.ReadyReplicas, controlPlane.Spec.Replicas),
+			})
+		}
+	}
+	return blockers, nil
+}
+
+// CheckMachineDeploymentsSettled blocks upgrades while a MachineDeployment is
+// still rolling out a change or has replicas which are not ready. The release
+// labels of node pools are only propagated on their next update, so the
+// rollout is read from the status instead.
+func CheckMachineDeploymentsSettled(m *Handler, oldCluster *capi.Cluster, newCluster *capi.Cluster) ([]UpgradeBlocker, error) {
+	var machineDeployments capi.MachineDeploymentList
+	err := m.K8sClient.CtrlClient().List(context.Background(), &machineDeployments, clusterSelector(newCluster)...)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+
+	var blockers []UpgradeBlocker
+	for i := range machineDeployments.Items {
+		md := &machineDeployments.Items[i]
+		reason := ""
+		switch {
+		case md.Status.ObservedGeneration < md.GetGeneration():
+			reason = "has changes which are not observed yet"
+		case md.Status.UpdatedReplicas != md.Status.Replicas:
+			reason = fmt.Sprintf("has %d of %d replicas updated", md.Status.UpdatedReplicas, md.Status.Replicas)
+		case md.Status.ReadyReplicas < md.Status.Replicas:
+			reason = fmt.Sprintf("has %d of %d replicas ready", md.Status.ReadyReplicas, md.Status.Replicas)
+		}
+		if reason != "" {
+			blockers = append(blockers, UpgradeBlocker{Kind: "MachineDeployment", Name: md.GetName(), Reason: reason})
+		}
+	}
+	return blockers, nil
+}
+
+func clusterSelector(cluster *capi.Cluster) []client.ListOption {
+	return []client.ListOption{
+		client.InNamespace(cluster.GetNamespace()),
+		client.MatchingLabels{label.Cluster: key.Cluster(cluster)},
+	}
+}
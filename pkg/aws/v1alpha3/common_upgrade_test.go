@@ -0,0 +1,191 @@
+package v1alpha3
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/k8smetadata/pkg/annotation"
+	"github.com/giantswarm/micrologger/microloggertest"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
+	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
+)
+
+func TestValidateUpgradeReadiness(t *testing.T) {
+	testCases := []struct {
+		name              string
+		paused            bool
+		annotations       map[string]string
+		controlPlaneReady int
+		mdReplicas        int32
+		mdUpdatedReplicas int32
+		mdReadyReplicas   int32
+		mdRelease         string
+
+		allowed bool
+		blocker string
+	}{
+		{
+			// Healthy cluster
+			name:              "case 0",
+			controlPlaneReady: 1,
+			mdReplicas:        3,
+			mdUpdatedReplicas: 3,
+			mdReadyReplicas:   3,
+			mdRelease:         "100.0.0",
+			allowed:           true,
+		},
+		{
+			// Paused cluster
+			name:              "case 1",
+			paused:            true,
+			controlPlaneReady: 1,
+			mdReplicas:        3,
+			mdUpdatedReplicas: 3,
+			mdReadyReplicas:   3,
+			mdRelease:         "100.0.0",
+			allowed:           false,
+			blocker:           "Cluster " + unittest.DefaultClusterID + " is paused",
+		},
+		{
+			// Cluster paused by annotation
+			name:              "case 2",
+			annotations:       map[string]string{capi.PausedAnnotation: ""},
+			controlPlaneReady: 1,
+			mdReplicas:        3,
+			mdUpdatedReplicas: 3,
+			mdReadyReplicas:   3,
+			mdRelease:         "100.0.0",
+			allowed:           false,
+			blocker:           capi.PausedAnnotation,
+		},
+		{
+			// Pending scheduled upgrade to another release
+			name: "case 3",
+			annotations: map[string]string{
+				annotation.UpdateScheduleTargetRelease: "100.2.0",
+				annotation.UpdateScheduleTargetTime:    "01 Jan 31 03:00 UTC",
+			},
+			controlPlaneReady: 1,
+			mdReplicas:        3,
+			mdUpdatedReplicas: 3,
+			mdReadyReplicas:   3,
+			mdRelease:         "100.0.0",
+			allowed:           false,
+			blocker:           "pending upgrade to release 100.2.0",
+		},
+		{
+			// Pending scheduled upgrade to the same release
+			name: "case 4",
+			annotations: map[string]string{
+				annotation.UpdateScheduleTargetRelease: "100.1.0",
+				annotation.UpdateScheduleTargetTime:    "01 Jan 31 03:00 UTC",
+			},
+			controlPlaneReady: 1,
+			mdReplicas:        3,
+			mdUpdatedReplicas: 3,
+			mdReadyReplicas:   3,
+			mdRelease:         "100.0.0",
+			allowed:           true,
+		},
+		{
+			// Control plane not ready
+			name:              "case 5",
+			controlPlaneReady: 0,
+			mdReplicas:        3,
+			mdUpdatedReplicas: 3,
+			mdReadyReplicas:   3,
+			mdRelease:         "100.0.0",
+			allowed:           false,
+			blocker:           "G8sControlPlane a2wax has 0 of 1 replicas ready",
+		},
+		{
+			// MachineDeployment still rolling out
+			name:              "case 6",
+			controlPlaneReady: 1,
+			mdReplicas:        3,
+			mdUpdatedReplicas: 1,
+			mdReadyReplicas:   3,
+			mdRelease:         "100.0.0",
+			allowed:           false,
+			blocker:           "MachineDeployment " + unittest.DefaultMachineDeploymentID + " has 1 of 3 replicas updated",
+		},
+		{
+			// MachineDeployment with replicas which are not ready
+			name:              "case 7",
+			controlPlaneReady: 1,
+			mdReplicas:        3,
+			mdUpdatedReplicas: 3,
+			mdReadyReplicas:   2,
+			mdRelease:         "100.0.0",
+			allowed:           false,
+			blocker:           "MachineDeployment " + unittest.DefaultMachineDeploymentID + " has 2 of 3 replicas ready",
+		},
+		{
+			// Settled node pool with a release label which is not propagated yet
+			name:              "case 8",
+			controlPlaneReady: 1,
+			mdReplicas:        3,
+			mdUpdatedReplicas: 3,
+			mdReadyReplicas:   3,
+			mdRelease:         "99.0.0",
+			allowed:           true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			handler := &Handler{
+				K8sClient: fakeK8sClient,
+				Logger:    microloggertest.New(),
+			}
+
+			controlPlane := unittest.DefaultG8sControlPlane()
+			md := unittest.DefaultMachineDeployment()
+			md.Labels[label.Release] = tc.mdRelease
+			awsMachineDeployment := unittest.DefaultAWSMachineDeployment()
+			awsMachineDeployment.Labels[label.Release] = "99.0.0"
+			for _, obj := range []client.Object{&controlPlane, md, awsMachineDeployment} {
+				err := fakeK8sClient.CtrlClient().Create(context.Background(), obj)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			// The status is not set on creation.
+			controlPlane.Status.ReadyReplicas = int32(tc.controlPlaneReady)
+			md.Status.Replicas = tc.mdReplicas
+			md.Status.UpdatedReplicas = tc.mdUpdatedReplicas
+			md.Status.ReadyReplicas = tc.mdReadyReplicas
+			for _, obj := range []client.Object{&controlPlane, md} {
+				err := fakeK8sClient.CtrlClient().Status().Update(context.Background(), obj)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			oldCluster := unittest.DefaultCluster()
+			newCluster := unittest.DefaultCluster()
+			newCluster.Labels[label.Release] = "100.1.0"
+			newCluster.Spec.Paused = tc.paused
+			newCluster.SetAnnotations(tc.annotations)
+
+			err := ValidateUpgradeReadiness(handler, oldCluster, newCluster, DefaultUpgradeChecks())
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed {
+				if !IsNotAllowed(err) {
+					t.Fatalf("expected not allowed error but got %v", err)
+				}
+				if !strings.Contains(err.Error(), tc.blocker) {
+					t.Fatalf("expected error to list %q but got %v", tc.blocker, err)
+				}
+			}
+		})
+	}
+}
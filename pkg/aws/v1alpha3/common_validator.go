@@ -7,10 +7,12 @@ import (
 
 	"github.com/blang/semver/v4"
 	"github.com/dylanmei/iso8601"
+	infrastructurev1alpha3 "github.com/giantswarm/apiextensions/v6/pkg/apis/infrastructure/v1alpha3"
 	"github.com/giantswarm/microerror"
 	securityv1alpha1 "github.com/giantswarm/organization-operator/api/v1alpha1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/internal/normalize"
@@ -67,6 +69,10 @@ func ValidateOrgNamespace(meta metav1.Object) error {
 		return nil
 	}
 
+	return validateOrgNamespaceName(meta)
+}
+
+func validateOrgNamespaceName(meta metav1.Object) error {
 	organization := key.Organization(meta)
 	if organization == "" {
 		return microerror.Maskf(organizationLabelNotFoundError, "Object %s Organization label %#q is empty.", meta.GetName(), label.Organization)
@@ -208,3 +214,61 @@ func ValidateOrganizationLabelContainsExistingOrganization(ctx context.Context,
 
 	return nil
 }
+
+// ValidateClusterDeletedFirst returns an error if the Cluster the object
+// belongs to still exists and is not being deleted, or if it can not be told
+// whether the Cluster exists.
+func ValidateClusterDeletedFirst(m *Handler, meta metav1.Object, kind string) error {
+	if key.Cluster(meta) == "" {
+		return microerror.Maskf(notAllowedError, "%s %s can not be deleted because it has no %s label. Add the label of the Cluster it belongs to.",
+			kind,
+			meta.GetName(),
+			label.Cluster)
+	}
+	cluster, err := FetchCluster(m, meta)
+	if IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return microerror.Mask(err)
+	}
+	if cluster.GetDeletionTimestamp() != nil {
+		return nil
+	}
+
+	return microerror.Maskf(notAllowedError, fmt.Sprintf("%s %s can not be deleted while Cluster %s still exists. Delete the Cluster instead.",
+		kind,
+		meta.GetName(),
+		cluster.GetName()),
+	)
+}
+
+// ValidateDeletionProtection returns an error if the Cluster is protected
+// against deletion by the deletion protection annotation.
+func ValidateDeletionProtection(cluster *capi.Cluster) error {
+	if cluster.GetAnnotations()[AnnotationDeletionProtection] != "true" {
+		return nil
+	}
+
+	return microerror.Maskf(notAllowedError, fmt.Sprintf("Cluster %s is protected against deletion. Remove the annotation %s to delete it.",
+		cluster.GetName(),
+		AnnotationDeletionProtection),
+	)
+}
+
+// ValidateNetworkPoolUnused returns an error if an AWSCluster which is not
+// being deleted references the NetworkPool.
+func ValidateNetworkPoolUnused(m *Handler, networkPool *infrastructurev1alpha3.NetworkPool) error {
+	awsClusters, err := FetchAWSClustersUsingNetworkPool(m, networkPool)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if len(awsClusters) > 0 {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("NetworkPool %s can not be deleted while it is used by AWSCluster %s/%s.",
+			networkPool.GetName(),
+			awsClusters[0].GetNamespace(),
+			awsClusters[0].GetName()),
+		)
+	}
+
+	return nil
+}
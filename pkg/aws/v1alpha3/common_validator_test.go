@@ -698,3 +698,98 @@ func TestValidateOperatorVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateClusterDeletedFirst(t *testing.T) {
+	testCases := []struct {
+		ctx  context.Context
+		name string
+
+		clusterID      string
+		clusterDeleted bool
+		apiUnavailable bool
+
+		allowed    bool
+		notAllowed bool
+	}{
+		{
+			// Deny deletion while the Cluster exists
+			name: "case 0",
+			ctx:  context.Background(),
+
+			clusterID:  unittest.DefaultClusterID,
+			allowed:    false,
+			notAllowed: true,
+		},
+		{
+			// Allow deletion while the Cluster is being deleted
+			name: "case 1",
+			ctx:  context.Background(),
+
+			clusterID:      unittest.DefaultClusterID,
+			clusterDeleted: true,
+			allowed:        true,
+		},
+		{
+			// Allow deletion if the Cluster does not exist
+			name: "case 2",
+			ctx:  context.Background(),
+
+			clusterID: "other",
+			allowed:   true,
+		},
+		{
+			// Deny deletion if the object has no cluster label
+			name: "case 3",
+			ctx:  context.Background(),
+
+			clusterID:  "",
+			allowed:    false,
+			notAllowed: true,
+		},
+		{
+			// Fail if it can not be told whether the Cluster exists
+			name: "case 4",
+			ctx:  context.Background(),
+
+			clusterID:      unittest.DefaultClusterID,
+			apiUnavailable: true,
+			allowed:        false,
+			notAllowed:     false,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			handler := &Handler{
+				K8sClient: fakeK8sClient,
+				Logger:    microloggertest.New(),
+			}
+			cluster := unittest.DefaultCluster()
+			if tc.clusterDeleted {
+				now := metav1.Now()
+				cluster.SetDeletionTimestamp(&now)
+				cluster.SetFinalizers([]string{"operatorkit.giantswarm.io/cluster-operator"})
+			}
+			err := fakeK8sClient.CtrlClient().Create(tc.ctx, cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.apiUnavailable {
+				handler.K8sClient = unittest.FailingK8sClient()
+			}
+
+			controlPlane := unittest.DefaultAWSControlPlane()
+			controlPlane.SetLabels(map[string]string{label.Cluster: tc.clusterID})
+			err = ValidateClusterDeletedFirst(handler, &controlPlane, "AWSControlPlane")
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && err == nil {
+				t.Fatal("expected an error but got none")
+			}
+			if tc.notAllowed != IsNotAllowed(err) {
+				t.Fatalf("expected not allowed to be %v but got %v", tc.notAllowed, err)
+			}
+		})
+	}
+}
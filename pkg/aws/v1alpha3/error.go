@@ -57,3 +57,12 @@ var organizationNotFoundError = &microerror.Error{
 func IsOrganizationNotFoundError(err error) bool {
 	return microerror.Cause(err) == organizationNotFoundError
 }
+
+var allocationFailedError = &microerror.Error{
+	Kind: "allocationFailedError",
+}
+
+// IsAllocationFailed asserts allocationFailedError.
+func IsAllocationFailed(err error) bool {
+	return microerror.Cause(err) == allocationFailedError
+}
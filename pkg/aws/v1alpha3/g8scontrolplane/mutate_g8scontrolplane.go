@@ -16,6 +16,7 @@ import (
 	admissionv1 "k8s.io/api/admission/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/giantswarm/aws-admission-controller/v4/config"
 	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
@@ -118,6 +119,12 @@ func (m *Mutator) MutateUpdate(request *admissionv1.AdmissionRequest) ([]mutator
 	patch = aws.MutateCAPILabel(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, g8sControlPlaneNewCR)
 	result = append(result, patch...)
 
+	patch, err = aws.MutateReleasePropagation(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, g8sControlPlaneNewCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	return result, nil
 }
 
@@ -132,6 +139,12 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 		return nil, microerror.Maskf(parsingFailedError, "unable to parse g8scontrol plane: %v", err)
 	}
 
+	patch, err = aws.MutateOrganizationLabel(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, g8sControlPlaneCR)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	patch, err = m.MutateReleaseVersion(*g8sControlPlaneCR)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -350,6 +363,10 @@ func (m *Mutator) Resource() string {
 	return "g8scontrolplane"
 }
 
+func (m *Mutator) GroupVersionKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{aws.G8sControlPlaneGVK}
+}
+
 func isUpdateFromSingleToHA(g8sControlPlaneNewCR infrastructurev1alpha3.G8sControlPlane, g8sControlPlaneOldCR infrastructurev1alpha3.G8sControlPlane, awsControlPlane infrastructurev1alpha3.AWSControlPlane) bool {
 	return g8sControlPlaneNewCR.Spec.Replicas == 3 && g8sControlPlaneOldCR.Spec.Replicas == 1 && len(awsControlPlane.Spec.AvailabilityZones) == 1
 }
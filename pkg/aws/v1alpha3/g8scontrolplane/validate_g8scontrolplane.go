@@ -9,6 +9,7 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/giantswarm/aws-admission-controller/v4/config"
 	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
@@ -19,6 +20,9 @@ import (
 type Validator struct {
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
+
+	gitopsGuard          aws.GitopsGuard
+	restrictedIdentities aws.RestrictedIdentities
 }
 
 func NewValidator(config config.Config) (*Validator, error) {
@@ -28,9 +32,13 @@ func NewValidator(config config.Config) (*Validator, error) {
 	if config.Logger == nil {
 		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
 	}
+
 	validator := &Validator{
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
+
+		gitopsGuard:          config.Policy.GitopsGuard,
+		restrictedIdentities: config.Policy.RestrictedIdentities,
 	}
 
 	return validator, nil
@@ -43,6 +51,27 @@ func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error
 	if request.Operation == admissionv1.Create {
 		return v.ValidateCreate(request)
 	}
+	if request.Operation == admissionv1.Delete {
+		return v.ValidateDelete(request)
+	}
+	return true, nil
+}
+
+func (v *Validator) ValidateDelete(request *admissionv1.AdmissionRequest) (bool, error) {
+	var g8sControlPlane infrastructurev1alpha3.G8sControlPlane
+	var err error
+
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &g8sControlPlane); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse g8scontrol plane: %v", err)
+	}
+	err = aws.ValidateClusterDeletedFirst(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, &g8sControlPlane, "G8sControlPlane")
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = aws.ValidateGitopsPaused(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, v.gitopsGuard, "G8sControlPlane", &g8sControlPlane, nil)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 	return true, nil
 }
 
@@ -54,7 +83,12 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Maskf(parsingFailedError, "unable to parse awscontrol plane: %v", err)
 	}
 
-	err = aws.ValidateOrgNamespace(&g8sControlPlane)
+	err = aws.ValidateNamespacePlacement(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "G8sControlPlane", nil, &g8sControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConstraints("G8sControlPlane", nil, &g8sControlPlane)
 	if err != nil {
 		return false, microerror.Mask(err)
 	}
@@ -83,11 +117,40 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 
 func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool, error) {
 	var g8sControlPlane infrastructurev1alpha3.G8sControlPlane
+	var g8sControlPlaneOld infrastructurev1alpha3.G8sControlPlane
 	var err error
 
 	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &g8sControlPlane); err != nil {
 		return false, microerror.Maskf(parsingFailedError, "unable to parse awscontrol plane: %v", err)
 	}
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &g8sControlPlaneOld); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse old g8scontrol plane: %v", err)
+	}
+
+	err = aws.ValidateGitopsPaused(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, v.gitopsGuard, "G8sControlPlane", &g8sControlPlaneOld, &g8sControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelEdit(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, v.restrictedIdentities, request.UserInfo, &g8sControlPlaneOld, &g8sControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConsistency(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "G8sControlPlane", &g8sControlPlaneOld, &g8sControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateNamespacePlacement(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "G8sControlPlane", &g8sControlPlaneOld, &g8sControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConstraints("G8sControlPlane", &g8sControlPlaneOld, &g8sControlPlane)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 
 	err = v.ReplicaCount(g8sControlPlane)
 	if err != nil {
@@ -157,3 +220,7 @@ func (m *Validator) Log(keyVals ...interface{}) {
 func (v *Validator) Resource() string {
 	return "g8scontrolplane"
 }
+
+func (v *Validator) GroupVersionKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{aws.G8sControlPlaneGVK}
+}
@@ -0,0 +1,162 @@
+package gitops
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/giantswarm/aws-admission-controller/v4/config"
+	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/key"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/metrics"
+)
+
+const (
+	// AnnotationBypass allows out-of-band edits of GitOps-managed objects in
+	// emergencies when it is set to "true" before the edit.
+	AnnotationBypass = "giantswarm.io/gitops-bypass"
+
+	lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+)
+
+// Validator denies updates of objects managed by a Flux Kustomization unless
+// they are made by Flux itself, by an identity with the GitopsEdit capability
+// or with the bypass annotation. It applies to any kind and is meant to run as
+// an additional stage of the validators of the managed kinds.
+type Validator struct {
+	logger micrologger.Logger
+
+	fluxServiceAccount   string
+	restrictedIdentities aws.RestrictedIdentities
+}
+
+func NewValidator(config config.Config) (*Validator, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	validator := &Validator{
+		logger: config.Logger,
+
+		fluxServiceAccount:   config.FluxServiceAccount,
+		restrictedIdentities: config.Policy.RestrictedIdentities,
+	}
+
+	return validator, nil
+}
+
+// Validate is a no-op unless a Flux service account is configured.
+func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error) {
+	if v.fluxServiceAccount == "" || request.Operation != admissionv1.Update {
+		return true, nil
+	}
+	return v.ValidateUpdate(request)
+}
+
+func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool, error) {
+	var err error
+
+	obj := &unstructured.Unstructured{}
+	oldObj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(request.Object.Raw); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse %s: %v", request.Kind.Kind, err)
+	}
+	if err := oldObj.UnmarshalJSON(request.OldObject.Raw); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse old %s: %v", request.Kind.Kind, err)
+	}
+
+	err = v.OutOfBandEditAllowed(request.UserInfo, oldObj, obj)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return true, nil
+}
+
+// OutOfBandEditAllowed returns a notAllowedError if the object is managed by
+// a Flux Kustomization before or after the update, the spec, labels or
+// annotations change and the user is neither Flux nor holds the GitopsEdit
+// capability.
+func (v *Validator) OutOfBandEditAllowed(userInfo authenticationv1.UserInfo, oldObj *unstructured.Unstructured, obj *unstructured.Unstructured) error {
+	owner := key.FluxKustomizationObjectKey(oldObj)
+	if owner == nil {
+		owner = key.FluxKustomizationObjectKey(obj)
+	}
+	if owner == nil {
+		return nil
+	}
+	if userInfo.Username == v.fluxServiceAccount || v.restrictedIdentities.GitopsEdit.Matches(userInfo) {
+		return nil
+	}
+	if !managedFieldsChanged(oldObj, obj) {
+		return nil
+	}
+	// The annotation has to be set on the object before the edit.
+	if oldObj.GetAnnotations()[AnnotationBypass] == "true" {
+		v.Log("level", "warning", "message", fmt.Sprintf("User %s bypassed the GitOps ownership of %s %s/%s by annotation %s.",
+			userInfo.Username, obj.GetKind(), obj.GetNamespace(), obj.GetName(), AnnotationBypass))
+		metrics.GitopsBypasses.WithLabelValues(obj.GetKind()).Inc()
+		return nil
+	}
+
+	return microerror.Maskf(notAllowedError, "%s %s/%s is managed by Flux Kustomization %s/%s and can only be changed through GitOps. In an emergency set the annotation %s to \"true\" before the change.",
+		obj.GetKind(),
+		obj.GetNamespace(),
+		obj.GetName(),
+		owner.Namespace,
+		owner.Name,
+		AnnotationBypass,
+	)
+}
+
+// managedFieldsChanged reports whether fields Flux reconciles from the
+// repository changed. Metadata maintained by controllers, e.g. finalizers, and
+// the status are ignored.
+func managedFieldsChanged(oldObj *unstructured.Unstructured, obj *unstructured.Unstructured) bool {
+	if !reflect.DeepEqual(oldObj.Object["spec"], obj.Object["spec"]) {
+		return true
+	}
+	if !reflect.DeepEqual(userLabels(oldObj, obj), userLabels(obj, obj)) {
+		return true
+	}
+	return !reflect.DeepEqual(userAnnotations(oldObj), userAnnotations(obj))
+}
+
+// userLabels returns the labels of labelsObj without the release and operator
+// version labels which the mutators propagate from the Cluster to obj. These
+// are validated to agree with the Cluster, so setting them is not an edit.
+func userLabels(labelsObj *unstructured.Unstructured, obj *unstructured.Unstructured) map[string]string {
+	labels := map[string]string{}
+	for k, v := range labelsObj.GetLabels() {
+		labels[k] = v
+	}
+	if obj.GetKind() == "Cluster" || key.Cluster(obj) == "" {
+		return labels
+	}
+	for _, l := range aws.ClusterLeadLabels() {
+		if obj.GetLabels()[l] != "" {
+			delete(labels, l)
+		}
+	}
+	return labels
+}
+
+func userAnnotations(obj *unstructured.Unstructured) map[string]string {
+	annotations := map[string]string{}
+	for k, v := range obj.GetAnnotations() {
+		if k == AnnotationBypass || k == lastAppliedConfigAnnotation {
+			continue
+		}
+		annotations[k] = v
+	}
+	return annotations
+}
+
+func (v *Validator) Log(keyVals ...interface{}) {
+	v.logger.Log(keyVals...)
+}
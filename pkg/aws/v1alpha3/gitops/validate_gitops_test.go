@@ -0,0 +1,277 @@
+package gitops
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/label"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/metrics"
+	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
+)
+
+func TestOutOfBandEditAllowed(t *testing.T) {
+	fluxLabels := map[string]string{
+		"kustomize.toolkit.fluxcd.io/name":      "clusters",
+		"kustomize.toolkit.fluxcd.io/namespace": "flux-system",
+	}
+	fluxServiceAccount := "system:serviceaccount:flux-system:kustomize-controller"
+
+	testCases := []struct {
+		name           string
+		disabled       bool
+		labels         map[string]string
+		newLabels      map[string]string
+		oldAnnotations map[string]string
+		annotations    map[string]string
+		finalizers     []string
+		paused         bool
+		user           authenticationv1.UserInfo
+
+		allowed  bool
+		bypassed bool
+	}{
+		{
+			// Edit of an object not managed by Flux
+			name:    "case 0",
+			paused:  true,
+			user:    authenticationv1.UserInfo{Username: "jane"},
+			allowed: true,
+		},
+		{
+			// Spec edit of a Flux-managed object by a user
+			name:    "case 1",
+			labels:  fluxLabels,
+			paused:  true,
+			user:    authenticationv1.UserInfo{Username: "jane"},
+			allowed: false,
+		},
+		{
+			// Spec edit of a Flux-managed object by Flux
+			name:    "case 2",
+			labels:  fluxLabels,
+			paused:  true,
+			user:    authenticationv1.UserInfo{Username: fluxServiceAccount},
+			allowed: true,
+		},
+		{
+			// Spec edit of a Flux-managed object by an admin
+			name:    "case 3",
+			labels:  fluxLabels,
+			paused:  true,
+			user:    authenticationv1.UserInfo{Username: "jane", Groups: []string{"giantswarm-admins"}},
+			allowed: true,
+		},
+		{
+			// Annotation edit of a Flux-managed object by a user
+			name:        "case 4",
+			labels:      fluxLabels,
+			annotations: map[string]string{"alpha.giantswarm.io/update-schedule-target-release": "20.0.0"},
+			user:        authenticationv1.UserInfo{Username: "jane"},
+			allowed:     false,
+		},
+		{
+			// Edit of a Flux-managed object with the bypass annotation
+			name:           "case 5",
+			labels:         fluxLabels,
+			oldAnnotations: map[string]string{AnnotationBypass: "true"},
+			annotations:    map[string]string{AnnotationBypass: "true"},
+			paused:         true,
+			user:           authenticationv1.UserInfo{Username: "jane"},
+			allowed:        true,
+			bypassed:       true,
+		},
+		{
+			// Removal of the Flux labels by a user
+			name:      "case 6",
+			labels:    fluxLabels,
+			newLabels: map[string]string{},
+			user:      authenticationv1.UserInfo{Username: "jane"},
+			allowed:   false,
+		},
+		{
+			// Finalizer change of a Flux-managed object by a controller
+			name:       "case 7",
+			labels:     fluxLabels,
+			finalizers: []string{"operatorkit.giantswarm.io/cluster-operator"},
+			user:       authenticationv1.UserInfo{Username: "system:serviceaccount:giantswarm:cluster-operator"},
+			allowed:    true,
+		},
+		{
+			// Validator disabled without Flux service account
+			name:     "case 8",
+			disabled: true,
+			labels:   fluxLabels,
+			paused:   true,
+			user:     authenticationv1.UserInfo{Username: "jane"},
+			allowed:  true,
+		},
+		{
+			// Edit of a Flux-managed object setting the bypass annotation
+			name:        "case 9",
+			labels:      fluxLabels,
+			annotations: map[string]string{AnnotationBypass: "true"},
+			paused:      true,
+			user:        authenticationv1.UserInfo{Username: "jane"},
+			allowed:     false,
+		},
+		{
+			// Setting the bypass annotation on a Flux-managed object
+			name:        "case 10",
+			labels:      fluxLabels,
+			annotations: map[string]string{AnnotationBypass: "true"},
+			user:        authenticationv1.UserInfo{Username: "jane"},
+			allowed:     true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			v := &Validator{
+				logger: microloggertest.New(),
+
+				fluxServiceAccount:   fluxServiceAccount,
+				restrictedIdentities: aws.RestrictedIdentities{GitopsEdit: &aws.Identities{Groups: []string{"giantswarm-admins"}}},
+			}
+			if tc.disabled {
+				v.fluxServiceAccount = ""
+			}
+
+			oldCluster := unittest.DefaultCluster()
+			for k, val := range tc.labels {
+				oldCluster.Labels[k] = val
+			}
+			oldCluster.SetAnnotations(tc.oldAnnotations)
+			cluster := oldCluster.DeepCopy()
+			if tc.newLabels != nil {
+				cluster.Labels = unittest.DefaultLabels()
+				for k, val := range tc.newLabels {
+					cluster.Labels[k] = val
+				}
+			}
+			cluster.SetAnnotations(tc.annotations)
+			cluster.SetFinalizers(tc.finalizers)
+			cluster.Spec.Paused = tc.paused
+
+			request, err := unittest.UpdateAdmissionRequest(oldCluster, cluster, metav1.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "Cluster"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			request.UserInfo = tc.user
+
+			bypasses := testutil.ToFloat64(metrics.GitopsBypasses.WithLabelValues("Cluster"))
+			allowed, err := v.Validate(&request)
+			if tc.allowed && (!allowed || err != nil) {
+				t.Fatalf("expected request to be allowed but got %v", err)
+			}
+			if !tc.allowed && !IsNotAllowed(err) {
+				t.Fatalf("expected not allowed error but got %v", err)
+			}
+			bypassed := testutil.ToFloat64(metrics.GitopsBypasses.WithLabelValues("Cluster")) > bypasses
+			if bypassed != tc.bypassed {
+				t.Fatalf("expected bypass to be counted %t but got %t", tc.bypassed, bypassed)
+			}
+		})
+	}
+}
+
+func TestOutOfBandEditAllowedAfterReleasePropagation(t *testing.T) {
+	fluxServiceAccount := "system:serviceaccount:flux-system:kustomize-controller"
+
+	testCases := []struct {
+		name        string
+		newLabels   map[string]string
+		description string
+
+		allowed bool
+	}{
+		{
+			// Release propagated from the Cluster
+			name:    "case 0",
+			allowed: true,
+		},
+		{
+			// Release propagated from the Cluster together with a label edit
+			name:      "case 1",
+			newLabels: map[string]string{"example-key": "example-value"},
+			allowed:   false,
+		},
+		{
+			// Release propagated from the Cluster together with a spec edit
+			name:        "case 2",
+			description: "edited",
+			allowed:     false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			handler := &aws.Handler{
+				K8sClient: fakeK8sClient,
+				Logger:    microloggertest.New(),
+			}
+			v := &Validator{
+				logger: microloggertest.New(),
+
+				fluxServiceAccount: fluxServiceAccount,
+			}
+
+			cluster := unittest.DefaultCluster()
+			cluster.Labels[label.Release] = "100.1.0"
+			err := fakeK8sClient.CtrlClient().Create(context.Background(), cluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+			release := unittest.NamedRelease("v100.1.0")
+			err = fakeK8sClient.CtrlClient().Create(context.Background(), &release)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			oldMachineDeployment := unittest.DefaultAWSMachineDeployment()
+			oldMachineDeployment.Labels["kustomize.toolkit.fluxcd.io/name"] = "clusters"
+			oldMachineDeployment.Labels["kustomize.toolkit.fluxcd.io/namespace"] = "flux-system"
+			machineDeployment := oldMachineDeployment.DeepCopy()
+			for k, val := range tc.newLabels {
+				machineDeployment.Labels[k] = val
+			}
+			if tc.description != "" {
+				machineDeployment.Spec.NodePool.Description = tc.description
+			}
+
+			// The mutation stage propagates the release of the Cluster.
+			patch, err := aws.MutateReleasePropagation(handler, machineDeployment)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range patch {
+				k := strings.TrimPrefix(p.Path, "/metadata/labels/")
+				k = strings.ReplaceAll(strings.ReplaceAll(k, "~1", "/"), "~0", "~")
+				machineDeployment.Labels[k] = p.Value.(string)
+			}
+
+			// The validation stage must not take the propagation for an edit.
+			request, err := unittest.UpdateAdmissionRequest(oldMachineDeployment, machineDeployment, metav1.GroupVersionKind{Group: "infrastructure.giantswarm.io", Version: "v1alpha3", Kind: "AWSMachineDeployment"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			request.UserInfo = authenticationv1.UserInfo{Username: "jane"}
+
+			allowed, err := v.Validate(&request)
+			if tc.allowed && (!allowed || err != nil) {
+				t.Fatalf("expected request to be allowed but got %v", err)
+			}
+			if !tc.allowed && !IsNotAllowed(err) {
+				t.Fatalf("expected not allowed error but got %v", err)
+			}
+		})
+	}
+}
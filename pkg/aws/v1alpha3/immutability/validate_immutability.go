@@ -0,0 +1,65 @@
+package immutability
+
+import (
+	"github.com/giantswarm/microerror"
+	"github.com/giantswarm/micrologger"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/giantswarm/aws-admission-controller/v4/config"
+	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
+)
+
+// Validator denies updates of fields which are immutable according to the
+// immutability policy. It applies to any kind and is meant to run as an
+// additional stage of the validators of all served kinds.
+type Validator struct {
+	logger micrologger.Logger
+
+	policy aws.ImmutabilityPolicy
+}
+
+func NewValidator(config config.Config) (*Validator, error) {
+	if config.Logger == nil {
+		return nil, microerror.Maskf(invalidConfigError, "%T.Logger must not be empty", config)
+	}
+
+	validator := &Validator{
+		logger: config.Logger,
+
+		policy: config.Policy.Immutability,
+	}
+
+	return validator, nil
+}
+
+func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error) {
+	if request.Operation == admissionv1.Update {
+		return v.ValidateUpdate(request)
+	}
+	return true, nil
+}
+
+func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool, error) {
+	var err error
+
+	obj := &unstructured.Unstructured{}
+	oldObj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(request.Object.Raw); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse %s: %v", request.Kind.Kind, err)
+	}
+	if err := oldObj.UnmarshalJSON(request.OldObject.Raw); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse old %s: %v", request.Kind.Kind, err)
+	}
+
+	err = aws.ValidateImmutableFields(v.policy, request.Kind.Kind, request.UserInfo, oldObj, obj)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return true, nil
+}
+
+func (v *Validator) Log(keyVals ...interface{}) {
+	v.logger.Log(keyVals...)
+}
@@ -0,0 +1,65 @@
+package immutability
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/giantswarm/micrologger/microloggertest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
+	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		clusterName string
+		replicas    int32
+
+		allowed bool
+	}{
+		{
+			// Scaling the MachineDeployment
+			name:        "case 0",
+			clusterName: unittest.DefaultClusterID,
+			replicas:    5,
+			allowed:     true,
+		},
+		{
+			// Moving the MachineDeployment to another cluster
+			name:        "case 1",
+			clusterName: "other",
+			allowed:     false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			v := &Validator{
+				logger: microloggertest.New(),
+
+				policy: aws.DefaultImmutabilityPolicy(),
+			}
+
+			oldMachineDeployment := unittest.DefaultMachineDeployment()
+			oldMachineDeployment.Spec.ClusterName = unittest.DefaultClusterID
+			machineDeployment := oldMachineDeployment.DeepCopy()
+			machineDeployment.Spec.ClusterName = tc.clusterName
+			machineDeployment.Spec.Replicas = &tc.replicas
+
+			request, err := unittest.UpdateAdmissionRequest(oldMachineDeployment, machineDeployment, metav1.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "MachineDeployment"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			allowed, err := v.Validate(&request)
+			if tc.allowed && (!allowed || err != nil) {
+				t.Fatalf("expected request to be allowed but got %v", err)
+			}
+			if !tc.allowed && !aws.IsNotAllowed(err) {
+				t.Fatalf("expected not allowed error but got %v", err)
+			}
+		})
+	}
+}
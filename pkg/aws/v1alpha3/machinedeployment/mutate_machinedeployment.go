@@ -10,6 +10,7 @@ import (
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 
 	"github.com/giantswarm/aws-admission-controller/v4/config"
@@ -81,6 +82,12 @@ func (m *Mutator) MutateCreate(request *admissionv1.AdmissionRequest) ([]mutator
 		return result, nil
 	}
 
+	patch, err = aws.MutateOrganizationLabel(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, machineDeployment)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	patch, err = m.MutateReleaseVersion(*machineDeployment)
 	if err != nil {
 		return nil, microerror.Mask(err)
@@ -147,6 +154,12 @@ func (m *Mutator) MutateUpdate(request *admissionv1.AdmissionRequest) ([]mutator
 
 	result = append(result, patch...)
 
+	patch, err = aws.MutateReleasePropagation(&aws.Handler{K8sClient: m.k8sClient, Logger: m.logger}, machineDeployment)
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	result = append(result, patch...)
+
 	return result, nil
 }
 
@@ -238,3 +251,7 @@ func (m *Mutator) Log(keyVals ...interface{}) {
 func (m *Mutator) Resource() string {
 	return "machinedeployment"
 }
+
+func (m *Mutator) GroupVersionKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{aws.MachineDeploymentGVK}
+}
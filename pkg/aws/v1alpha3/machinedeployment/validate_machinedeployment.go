@@ -8,6 +8,7 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	capi "sigs.k8s.io/cluster-api/api/v1beta1"
 
 	"github.com/giantswarm/aws-admission-controller/v4/config"
@@ -18,6 +19,9 @@ import (
 type Validator struct {
 	k8sClient k8sclient.Interface
 	logger    micrologger.Logger
+
+	gitopsGuard          aws.GitopsGuard
+	restrictedIdentities aws.RestrictedIdentities
 }
 
 func NewValidator(config config.Config) (*Validator, error) {
@@ -31,6 +35,9 @@ func NewValidator(config config.Config) (*Validator, error) {
 	validator := &Validator{
 		k8sClient: config.K8sClient,
 		logger:    config.Logger,
+
+		gitopsGuard:          config.Policy.GitopsGuard,
+		restrictedIdentities: config.Policy.RestrictedIdentities,
 	}
 
 	return validator, nil
@@ -40,6 +47,9 @@ func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error
 	if request.Operation == admissionv1.Create {
 		return v.ValidateCreate(request)
 	}
+	if request.Operation == admissionv1.Update {
+		return v.ValidateUpdate(request)
+	}
 	return true, nil
 }
 
@@ -68,6 +78,16 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 		return false, microerror.Mask(err)
 	}
 
+	err = aws.ValidateNamespacePlacement(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "MachineDeployment", nil, &machineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConstraints("MachineDeployment", nil, &machineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
 	err = aws.ValidateOperatorVersion(&machineDeployment)
 	if err != nil {
 		return false, microerror.Mask(err)
@@ -76,6 +96,53 @@ func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool,
 	return true, nil
 }
 
+func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool, error) {
+	var err error
+
+	var machineDeployment capi.MachineDeployment
+	var machineDeploymentOld capi.MachineDeployment
+	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &machineDeployment); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse machinedeployment: %v", err)
+	}
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &machineDeploymentOld); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse old machinedeployment: %v", err)
+	}
+	capi, err := aws.IsCAPIRelease(&machineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	if capi {
+		return true, nil
+	}
+
+	err = aws.ValidateGitopsPaused(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, v.gitopsGuard, "MachineDeployment", &machineDeploymentOld, &machineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelEdit(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, v.restrictedIdentities, request.UserInfo, &machineDeploymentOld, &machineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConsistency(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "MachineDeployment", &machineDeploymentOld, &machineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateNamespacePlacement(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, "MachineDeployment", &machineDeploymentOld, &machineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	err = aws.ValidateLabelConstraints("MachineDeployment", &machineDeploymentOld, &machineDeployment)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return true, nil
+}
+
 func (v *Validator) ValidateCluster(machineDeployment capi.MachineDeployment) error {
 	var err error
 
@@ -100,3 +167,7 @@ func (v *Validator) Log(keyVals ...interface{}) {
 func (v *Validator) Resource() string {
 	return "machinedeployment"
 }
+
+func (v *Validator) GroupVersionKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{aws.MachineDeploymentGVK}
+}
@@ -1,9 +1,7 @@
 package networkpool
 
 import (
-	"context"
 	"fmt"
-	"net"
 	"time"
 
 	infrastructurev1alpha3 "github.com/giantswarm/apiextensions/v6/pkg/apis/infrastructure/v1alpha3"
@@ -12,11 +10,23 @@ import (
 	"github.com/giantswarm/microerror"
 	"github.com/giantswarm/micrologger"
 	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/giantswarm/aws-admission-controller/v4/config"
+	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/cidr"
 	"github.com/giantswarm/aws-admission-controller/v4/pkg/validator"
 )
 
+// prefixRules are the allowed sizes of NetworkPools. Node subnets on AWS are
+// IPv4 only, so NetworkPools are as well.
+var prefixRules = cidr.PrefixRules{
+	cidr.IPv4: {Min: 16, Max: 24},
+}
+
+// privateNetworks are the private address ranges defined in RFC1918.
+var privateNetworks = []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"}
+
 type Validator struct {
 	dockerCIDR               string
 	ipamNetworkCIDR          string
@@ -54,12 +64,44 @@ func NewValidator(config config.Config) (*Validator, error) {
 }
 
 func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error) {
+	if request.Operation == admissionv1.Create {
+		return v.ValidateCreate(request)
+	}
+	if request.Operation == admissionv1.Update {
+		return v.ValidateUpdate(request)
+	}
+	if request.Operation == admissionv1.Delete {
+		return v.ValidateDelete(request)
+	}
+	return true, nil
+}
+
+func (v *Validator) ValidateDelete(request *admissionv1.AdmissionRequest) (bool, error) {
+	var networkPool infrastructurev1alpha3.NetworkPool
+	var err error
+
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &networkPool); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse networkpool: %v", err)
+	}
+	err = aws.ValidateNetworkPoolUnused(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, &networkPool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return true, nil
+}
+
+func (v *Validator) ValidateCreate(request *admissionv1.AdmissionRequest) (bool, error) {
 	var networkPool infrastructurev1alpha3.NetworkPool
 	var err error
 
 	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &networkPool); err != nil {
 		return false, microerror.Maskf(parsingFailedError, "unable to parse networkpool: %v", err)
 	}
+	err = v.cidrBlockValid(networkPool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
 	err = v.networkPoolAllowed(networkPool)
 	if err != nil {
 		return false, microerror.Mask(err)
@@ -68,68 +110,133 @@ func (v *Validator) Validate(request *admissionv1.AdmissionRequest) (bool, error
 	return true, nil
 }
 
-func (v *Validator) networkPoolAllowed(np infrastructurev1alpha3.NetworkPool) error {
+func (v *Validator) ValidateUpdate(request *admissionv1.AdmissionRequest) (bool, error) {
+	var networkPool infrastructurev1alpha3.NetworkPool
+	var networkPoolOld infrastructurev1alpha3.NetworkPool
 	var err error
-	var fetch func() error
-	var networkCIDRs []string
-	var networkPoolList infrastructurev1alpha3.NetworkPoolList
 
-	// Fetch all NetworkPools.
-	{
-		v.Log("level", "debug", "message", "Fetching all NetworkPools")
-		fetch = func() error {
-			ctx := context.Background()
-
-			err = v.k8sClient.CtrlClient().List(
-				ctx,
-				&networkPoolList,
-			)
-			if err != nil {
-				return microerror.Maskf(notFoundError, "failed to fetch NetworkPools: %v", err)
-			}
+	if _, _, err := validator.Deserializer.Decode(request.Object.Raw, nil, &networkPool); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse networkpool: %v", err)
+	}
+	if _, _, err := validator.Deserializer.Decode(request.OldObject.Raw, nil, &networkPoolOld); err != nil {
+		return false, microerror.Maskf(parsingFailedError, "unable to parse old networkpool: %v", err)
+	}
+	if networkPool.Spec.CIDRBlock == networkPoolOld.Spec.CIDRBlock {
+		return true, nil
+	}
+	err = v.cidrBlockValid(networkPool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.cidrBlockChangeAllowed(networkPool, networkPoolOld)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+	err = v.networkPoolAllowed(networkPool)
+	if err != nil {
+		return false, microerror.Mask(err)
+	}
+
+	return true, nil
+}
 
+// cidrBlockValid checks that the NetworkPool uses a private network range of
+// an acceptable size.
+func (v *Validator) cidrBlockValid(np infrastructurev1alpha3.NetworkPool) error {
+	customNet, err := cidr.Parse(np.Spec.CIDRBlock)
+	if err != nil {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("NetworkPool %s CIDR block %s is invalid: %v", np.Name, np.Spec.CIDRBlock, err))
+	}
+	err = prefixRules.Validate(customNet)
+	if err != nil {
+		return microerror.Maskf(notAllowedError, fmt.Sprintf("NetworkPool %s CIDR block is invalid: %v", np.Name, err))
+	}
+	for _, privateCIDR := range privateNetworks {
+		privateNet, err := cidr.Parse(privateCIDR)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+		if contained, _ := cidr.Contains(privateNet, customNet); contained {
 			return nil
 		}
 	}
 
-	{
-		b := backoff.NewMaxRetries(3, 10*time.Millisecond)
-		err = backoff.Retry(fetch, b)
-		if IsNotFound(err) {
-			v.Log("level", "debug", "message", fmt.Sprintf("No NetworkPool could be found: %v", err))
-		} else if err != nil {
-			return microerror.Mask(err)
-		}
+	return microerror.Maskf(notAllowedError, fmt.Sprintf("NetworkPool %s CIDR block %s must be within one of the private network ranges %v",
+		np.Name,
+		np.Spec.CIDRBlock,
+		privateNetworks),
+	)
+}
+
+// cidrBlockChangeAllowed only allows widening the CIDR block of a NetworkPool
+// once it is used by an AWSCluster, because the node subnets of the cluster
+// have been allocated from it.
+func (v *Validator) cidrBlockChangeAllowed(np infrastructurev1alpha3.NetworkPool, npOld infrastructurev1alpha3.NetworkPool) error {
+	awsClusters, err := aws.FetchAWSClustersUsingNetworkPool(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, &np)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if len(awsClusters) == 0 {
+		return nil
 	}
 
-	// append all CIDRs from existing NetworkPools
-	for _, networkPool := range networkPoolList.Items {
-		// we do not want to add the same networkpool when updating, e.g. when extending the IP range
-		if networkPool.Name == np.Name && networkPool.Namespace == np.Namespace {
-			continue
-		}
-		networkCIDRs = append(networkCIDRs, networkPool.Spec.CIDRBlock)
+	customNet, err := cidr.Parse(np.Spec.CIDRBlock)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	oldNet, err := cidr.Parse(npOld.Spec.CIDRBlock)
+	if err != nil {
+		return microerror.Mask(err)
+	}
+	if widened, _ := cidr.Contains(customNet, oldNet); widened {
+		return nil
 	}
 
-	// append Docker CIDR, Kubernetes cluster IP range and tenant cluster CIDR
-	networkCIDRs = append(networkCIDRs, v.dockerCIDR, v.ipamNetworkCIDR, v.kubernetesClusterIPRange)
+	return microerror.Maskf(notAllowedError, fmt.Sprintf("NetworkPool %s is used by AWSCluster %s. Its CIDR block can only be widened to contain %s, got %s.",
+		np.Name,
+		awsClusters[0].Name,
+		npOld.Spec.CIDRBlock,
+		np.Spec.CIDRBlock),
+	)
+}
+
+func (v *Validator) networkPoolAllowed(np infrastructurev1alpha3.NetworkPool) error {
+	var err error
+	var reservations aws.Reservations
 
-	// parse CIDRBlock from NetworkPool
-	customNet, err := mustParseCIDR(np.Spec.CIDRBlock)
+	global, err := aws.GlobalReservations(v.dockerCIDR, v.ipamNetworkCIDR, v.kubernetesClusterIPRange)
 	if err != nil {
 		return microerror.Mask(err)
 	}
 
-	for _, cidr := range networkCIDRs {
-		net, err := mustParseCIDR(cidr)
+	// Fetch all reserved network ranges.
+	{
+		v.Log("level", "debug", "message", "Fetching all reserved network ranges")
+		fetch := func() error {
+			reservations, err = aws.FetchReservations(&aws.Handler{K8sClient: v.k8sClient, Logger: v.logger}, global)
+			if err != nil {
+				return microerror.Mask(err)
+			}
+			return nil
+		}
+
+		b := backoff.NewMaxRetries(3, 10*time.Millisecond)
+		err = backoff.Retry(fetch, b)
 		if err != nil {
 			return microerror.Mask(err)
 		}
-		// in case of overlapping network ranges we do not allow creating this NetworkPool
-		if intersect(customNet, net) {
-			return microerror.Maskf(intersectFailedError, fmt.Sprintf("network pool %s intersect with an existing CIDR %s", customNet.String(), net.String()))
-		}
+	}
+
+	// parse CIDRBlock from NetworkPool
+	customNet, err := cidr.Parse(np.Spec.CIDRBlock)
+	if err != nil {
+		return microerror.Mask(err)
+	}
 
+	// in case of overlapping network ranges we do not allow creating this NetworkPool
+	conflict, ok := reservations.Conflict(aws.Reservation{NetworkPool: np.Name, Network: customNet, Owner: aws.NetworkPoolOwner(&np)})
+	if ok {
+		return microerror.Maskf(intersectFailedError, fmt.Sprintf("network pool %s intersect with the %s %s", customNet.String(), conflict.Owner, conflict.Network.String()))
 	}
 
 	return nil
@@ -143,16 +250,6 @@ func (v *Validator) Resource() string {
 	return "networkpool"
 }
 
-func mustParseCIDR(cidr string) (*net.IPNet, error) {
-	_, ipNet, err := net.ParseCIDR(cidr)
-	if err != nil {
-		return nil, microerror.Mask(err)
-	}
-
-	return ipNet, nil
-}
-
-// intersect checks if network ranges overlap
-func intersect(n1, n2 *net.IPNet) bool {
-	return n2.Contains(n1.IP) || n1.Contains(n2.IP)
+func (v *Validator) GroupVersionKinds() []schema.GroupVersionKind {
+	return []schema.GroupVersionKind{aws.NetworkPoolGVK}
 }
@@ -2,11 +2,17 @@ package networkpool
 
 import (
 	"context"
+	"encoding/json"
 	"strconv"
 	"testing"
 
+	"github.com/giantswarm/k8smetadata/pkg/annotation"
 	"github.com/giantswarm/micrologger/microloggertest"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
+	aws "github.com/giantswarm/aws-admission-controller/v4/pkg/aws/v1alpha3"
 	unittest "github.com/giantswarm/aws-admission-controller/v4/pkg/unittest/v1alpha3"
 )
 
@@ -19,6 +25,7 @@ func TestNetworkPool(t *testing.T) {
 		kubernetesClusterIPRange string
 		networkPoolCIDRs         []string
 		tenantNetworkCIDR        string
+		ciliumCIDR               string
 
 		allowed bool
 	}{
@@ -79,6 +86,18 @@ func TestNetworkPool(t *testing.T) {
 			kubernetesClusterIPRange: "10.35.0.0/17",
 			tenantNetworkCIDR:        "10.0.0.0/16",
 
+			allowed: true,
+		},
+		{
+			// Cilium pod CIDR of a cluster not using the NetworkPool
+			name:                     "case 5",
+			ctx:                      context.Background(),
+			customNetworkCIDR:        "192.168.178.0/24",
+			dockerCIDR:               "172.18.224.1/19",
+			kubernetesClusterIPRange: "10.35.0.0/17",
+			tenantNetworkCIDR:        "10.0.0.0/16",
+			ciliumCIDR:               "192.168.0.0/16",
+
 			allowed: true,
 		},
 	}
@@ -104,6 +123,15 @@ func TestNetworkPool(t *testing.T) {
 				}
 			}
 
+			if tc.ciliumCIDR != "" {
+				cluster := unittest.DefaultCluster()
+				cluster.SetAnnotations(map[string]string{annotation.CiliumPodCidr: tc.ciliumCIDR})
+				err = fakeK8sClient.CtrlClient().Create(tc.ctx, cluster)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
 			// simulate a admission request for NetworkPool creation
 			request, err := unittest.DefaultAdmissionRequestNetworkPool(tc.customNetworkCIDR)
 			if err != nil {
@@ -116,3 +144,258 @@ func TestNetworkPool(t *testing.T) {
 		})
 	}
 }
+
+func TestCIDRBlockValid(t *testing.T) {
+	testCases := []struct {
+		name      string
+		cidrBlock string
+
+		allowed bool
+	}{
+		{
+			// Private /16
+			name:      "case 0",
+			cidrBlock: "172.16.0.0/16",
+			allowed:   true,
+		},
+		{
+			// Private /24
+			name:      "case 1",
+			cidrBlock: "192.168.178.0/24",
+			allowed:   true,
+		},
+		{
+			// Public range
+			name:      "case 2",
+			cidrBlock: "8.8.0.0/16",
+			allowed:   false,
+		},
+		{
+			// Prefix too short
+			name:      "case 3",
+			cidrBlock: "10.0.0.0/8",
+			allowed:   false,
+		},
+		{
+			// Prefix too long
+			name:      "case 4",
+			cidrBlock: "10.0.0.0/28",
+			allowed:   false,
+		},
+		{
+			// Host bits set, network is within 172.16.0.0/12
+			name:      "case 5",
+			cidrBlock: "172.31.255.0/16",
+			allowed:   true,
+		},
+		{
+			// IPv6
+			name:      "case 6",
+			cidrBlock: "fd00::/16",
+			allowed:   false,
+		},
+		{
+			// No CIDR
+			name:      "case 7",
+			cidrBlock: "10.0.0.0",
+			allowed:   false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			validate := &Validator{
+				k8sClient: unittest.FakeK8sClient(),
+				logger:    microloggertest.New(),
+			}
+
+			err := validate.cidrBlockValid(*unittest.DefaultNetworkPool(tc.cidrBlock))
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.allowed && !IsNotAllowed(err) {
+				t.Fatalf("expected not allowed error but got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	testCases := []struct {
+		name         string
+		oldCIDRBlock string
+		newCIDRBlock string
+		referenced   bool
+
+		allowed bool
+	}{
+		{
+			// Unused NetworkPool may change
+			name:         "case 0",
+			oldCIDRBlock: "172.16.0.0/24",
+			newCIDRBlock: "172.17.0.0/24",
+			allowed:      true,
+		},
+		{
+			// Used NetworkPool may be widened
+			name:         "case 1",
+			oldCIDRBlock: "172.16.0.0/24",
+			newCIDRBlock: "172.16.0.0/20",
+			referenced:   true,
+			allowed:      true,
+		},
+		{
+			// Used NetworkPool may not be moved
+			name:         "case 2",
+			oldCIDRBlock: "172.16.0.0/24",
+			newCIDRBlock: "172.17.0.0/24",
+			referenced:   true,
+			allowed:      false,
+		},
+		{
+			// Used NetworkPool may not be narrowed
+			name:         "case 3",
+			oldCIDRBlock: "172.16.0.0/20",
+			newCIDRBlock: "172.16.0.0/24",
+			referenced:   true,
+			allowed:      false,
+		},
+		{
+			// Unchanged CIDR block
+			name:         "case 4",
+			oldCIDRBlock: "172.16.0.0/24",
+			newCIDRBlock: "172.16.0.0/24",
+			referenced:   true,
+			allowed:      true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				dockerCIDR:               "172.18.224.1/19",
+				ipamNetworkCIDR:          "10.0.0.0/16",
+				k8sClient:                fakeK8sClient,
+				kubernetesClusterIPRange: "10.35.0.0/17",
+				logger:                   microloggertest.New(),
+			}
+
+			oldNetworkPool := unittest.DefaultNetworkPool(tc.oldCIDRBlock)
+			newNetworkPool := oldNetworkPool.DeepCopy()
+			newNetworkPool.Spec.CIDRBlock = tc.newCIDRBlock
+
+			if tc.referenced {
+				awsCluster := unittest.DefaultAWSCluster()
+				awsCluster.SetNamespace(oldNetworkPool.GetNamespace())
+				awsCluster.Spec.Provider.Nodes.NetworkPool = oldNetworkPool.GetName()
+				err := fakeK8sClient.CtrlClient().Create(context.Background(), awsCluster)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			oldRaw, err := json.Marshal(oldNetworkPool)
+			if err != nil {
+				t.Fatal(err)
+			}
+			newRaw, err := json.Marshal(newNetworkPool)
+			if err != nil {
+				t.Fatal(err)
+			}
+			request := admissionv1.AdmissionRequest{
+				Kind:      metav1.GroupVersionKind(aws.NetworkPoolGVK),
+				Operation: admissionv1.Update,
+				Object:    runtime.RawExtension{Raw: newRaw},
+				OldObject: runtime.RawExtension{Raw: oldRaw},
+			}
+
+			allowed, err := validate.Validate(&request)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if allowed != tc.allowed {
+				t.Fatalf("expected %v to not differ from %v", allowed, tc.allowed)
+			}
+		})
+	}
+}
+
+func TestValidateDelete(t *testing.T) {
+	testCases := []struct {
+		name             string
+		referenced       bool
+		clusterDeleting  bool
+		clusterNamespace string
+
+		allowed bool
+	}{
+		{
+			// NetworkPool is not used
+			name:    "case 0",
+			allowed: true,
+		},
+		{
+			// NetworkPool is used by an AWSCluster
+			name:       "case 1",
+			referenced: true,
+			allowed:    false,
+		},
+		{
+			// NetworkPool is used by an AWSCluster which is being deleted
+			name:            "case 2",
+			referenced:      true,
+			clusterDeleting: true,
+			allowed:         true,
+		},
+		{
+			// NetworkPool is used by an AWSCluster in an org namespace
+			name:             "case 3",
+			referenced:       true,
+			clusterNamespace: "org-example",
+			allowed:          false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			fakeK8sClient := unittest.FakeK8sClient()
+			validate := &Validator{
+				k8sClient: fakeK8sClient,
+				logger:    microloggertest.New(),
+			}
+
+			networkPool := unittest.DefaultNetworkPool("172.16.0.0/16")
+			awsCluster := unittest.DefaultAWSCluster()
+			awsCluster.SetNamespace(networkPool.GetNamespace())
+			if tc.clusterNamespace != "" {
+				awsCluster.SetNamespace(tc.clusterNamespace)
+			}
+			if tc.referenced {
+				awsCluster.Spec.Provider.Nodes.NetworkPool = networkPool.GetName()
+			}
+			if tc.clusterDeleting {
+				now := metav1.Now()
+				awsCluster.SetDeletionTimestamp(&now)
+				awsCluster.SetFinalizers([]string{"operatorkit.giantswarm.io/aws-operator"})
+			}
+			err := fakeK8sClient.CtrlClient().Create(context.Background(), awsCluster)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			request, err := unittest.DeleteAdmissionRequest(networkPool, metav1.GroupVersionKind(aws.NetworkPoolGVK))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			allowed, err := validate.Validate(&request)
+			if tc.allowed && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if allowed != tc.allowed {
+				t.Fatalf("expected %v to not differ from %v", allowed, tc.allowed)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Package cidr implements parsing and comparison of IPv4 and IPv6 network
+// ranges, including dual-stack lists of one range per address family.
+package cidr
+
+import (
+	"net"
+	"strings"
+
+	"github.com/giantswarm/microerror"
+)
+
+// Family is the address family of a network range.
+type Family string
+
+const (
+	IPv4 Family = "IPv4"
+	IPv6 Family = "IPv6"
+)
+
+// PrefixRule bounds the prefix length of network ranges of one family.
+type PrefixRule struct {
+	// Min is the prefix length of the largest allowed network.
+	Min int
+	// Max is the prefix length of the smallest allowed network.
+	Max int
+}
+
+// PrefixRules are the allowed prefix lengths per family. Network ranges of
+// families without a rule are not allowed.
+type PrefixRules map[Family]PrefixRule
+
+// Validate returns a prefixLengthError if the network is of a family without
+// rule or its prefix length is out of the bounds of its family.
+func (r PrefixRules) Validate(network *net.IPNet) error {
+	family := FamilyOf(network)
+	rule, ok := r[family]
+	if !ok {
+		return microerror.Maskf(prefixLengthError, "%s network %s is not supported", family, network.String())
+	}
+	ones, _ := network.Mask.Size()
+	if ones < rule.Min || ones > rule.Max {
+		return microerror.Maskf(prefixLengthError, "%s network %s must have a prefix length between /%d and /%d", family, network.String(), rule.Min, rule.Max)
+	}
+	return nil
+}
+
+// ValidateList validates every network of the list.
+func (r PrefixRules) ValidateList(networks []*net.IPNet) error {
+	for _, network := range networks {
+		err := r.Validate(network)
+		if err != nil {
+			return microerror.Mask(err)
+		}
+	}
+	return nil
+}
+
+// Parse parses a single network range in CIDR notation. The host bits of the
+// address are cleared.
+func Parse(s string) (*net.IPNet, error) {
+	_, network, err := net.ParseCIDR(strings.TrimSpace(s))
+	if err != nil {
+		return nil, microerror.Maskf(invalidCIDRError, "%#q is not a valid CIDR: %v", s, err)
+	}
+	return network, nil
+}
+
+// ParseList parses a comma separated list of network ranges. A list is either
+// a single range or a dual-stack pair of one IPv4 and one IPv6 range.
+func ParseList(s string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, part := range strings.Split(s, ",") {
+		network, err := Parse(part)
+		if err != nil {
+			return nil, microerror.Mask(err)
+		}
+		networks = append(networks, network)
+	}
+	if len(networks) > 2 {
+		return nil, microerror.Maskf(invalidCIDRError, "%#q contains more than one network per family", s)
+	}
+	if len(networks) == 2 && FamilyOf(networks[0]) == FamilyOf(networks[1]) {
+		return nil, microerror.Maskf(invalidCIDRError, "%#q contains two %s networks, a dual-stack list needs one IPv4 and one IPv6 network", s, FamilyOf(networks[0]))
+	}
+	return networks, nil
+}
+
+// FamilyOf returns the address family of the network.
+func FamilyOf(network *net.IPNet) Family {
+	if network.IP.To4() != nil {
+		return IPv4
+	}
+	return IPv6
+}
+
+// Overlap reports whether the networks share any address. Networks of
+// different families can not be compared and return a mixedFamilyError.
+func Overlap(a, b *net.IPNet) (bool, error) {
+	if FamilyOf(a) != FamilyOf(b) {
+		return false, microerror.Maskf(mixedFamilyError, "can not compare %s network %s with %s network %s", FamilyOf(a), a.String(), FamilyOf(b), b.String())
+	}
+	return a.Contains(b.IP) || b.Contains(a.IP), nil
+}
+
+// Contains reports whether the outer network contains the whole inner
+// network. Networks of different families return a mixedFamilyError.
+func Contains(outer, inner *net.IPNet) (bool, error) {
+	if FamilyOf(outer) != FamilyOf(inner) {
+		return false, microerror.Maskf(mixedFamilyError, "can not compare %s network %s with %s network %s", FamilyOf(outer), outer.String(), FamilyOf(inner), inner.String())
+	}
+	outerOnes, _ := outer.Mask.Size()
+	innerOnes, _ := inner.Mask.Size()
+	return outer.Contains(inner.IP) && outerOnes <= innerOnes, nil
+}
+
+// String joins the networks to a comma separated list.
+func String(networks []*net.IPNet) string {
+	var s []string
+	for _, network := range networks {
+		s = append(s, network.String())
+	}
+	return strings.Join(s, ",")
+}
+
+// Last returns the last address of the network.
+func Last(network *net.IPNet) net.IP {
+	last := make(net.IP, len(network.IP))
+	for i := range network.IP {
+		last[i] = network.IP[i] | ^network.Mask[i]
+	}
+	return last
+}
+
+// Next returns the network of the same size directly following the given
+// network. It returns false if the network is the last of its family.
+func Next(network *net.IPNet) (*net.IPNet, bool) {
+	next := make(net.IP, len(network.IP))
+	copy(next, Last(network))
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			return &net.IPNet{IP: next, Mask: network.Mask}, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,210 @@
+package cidr
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestParseList(t *testing.T) {
+	testCases := []struct {
+		name string
+		list string
+
+		expected string
+		valid    bool
+	}{
+		{
+			// Single IPv4 network
+			name:     "case 0",
+			list:     "10.0.0.0/16",
+			expected: "10.0.0.0/16",
+			valid:    true,
+		},
+		{
+			// Single IPv6 network
+			name:     "case 1",
+			list:     "fd00:10::/56",
+			expected: "fd00:10::/56",
+			valid:    true,
+		},
+		{
+			// Dual-stack list, host bits are cleared
+			name:     "case 2",
+			list:     "10.0.1.0/16, fd00:10::1/56",
+			expected: "10.0.0.0/16,fd00:10::/56",
+			valid:    true,
+		},
+		{
+			// Two networks of the same family
+			name:  "case 3",
+			list:  "10.0.0.0/16,10.1.0.0/16",
+			valid: false,
+		},
+		{
+			// Three networks
+			name:  "case 4",
+			list:  "10.0.0.0/16,fd00:10::/56,fd00:11::/56",
+			valid: false,
+		},
+		{
+			// Invalid network
+			name:  "case 5",
+			list:  "10.0.0.0",
+			valid: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			networks, err := ParseList(tc.list)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid {
+				if !IsInvalidCIDR(err) {
+					t.Fatalf("expected invalid CIDR error but got %v", err)
+				}
+				return
+			}
+			if String(networks) != tc.expected {
+				t.Fatalf("expected %s but got %s", tc.expected, String(networks))
+			}
+		})
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	testCases := []struct {
+		name string
+		a    string
+		b    string
+
+		overlap     bool
+		mixedFamily bool
+	}{
+		{
+			// Overlapping IPv4 networks
+			name:    "case 0",
+			a:       "10.0.0.0/8",
+			b:       "10.1.0.0/16",
+			overlap: true,
+		},
+		{
+			// Disjunct IPv4 networks
+			name:    "case 1",
+			a:       "10.0.0.0/16",
+			b:       "10.1.0.0/16",
+			overlap: false,
+		},
+		{
+			// Overlapping IPv6 networks
+			name:    "case 2",
+			a:       "fd00::/8",
+			b:       "fd00:10::/56",
+			overlap: true,
+		},
+		{
+			// Mixed families
+			name:        "case 3",
+			a:           "10.0.0.0/8",
+			b:           "fd00::/8",
+			mixedFamily: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			a, err := Parse(tc.a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := Parse(tc.b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			overlap, err := Overlap(a, b)
+			if tc.mixedFamily {
+				if !IsMixedFamily(err) {
+					t.Fatalf("expected mixed family error but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if overlap != tc.overlap {
+				t.Fatalf("expected overlap %t but got %t", tc.overlap, overlap)
+			}
+		})
+	}
+}
+
+func TestPrefixRules(t *testing.T) {
+	rules := PrefixRules{
+		IPv4: {Min: 16, Max: 24},
+		IPv6: {Min: 48, Max: 64},
+	}
+	ipv4Only := PrefixRules{
+		IPv4: {Min: 16, Max: 24},
+	}
+
+	testCases := []struct {
+		name  string
+		rules PrefixRules
+		cidr  string
+
+		valid bool
+	}{
+		{
+			// IPv4 within bounds
+			name:  "case 0",
+			rules: rules,
+			cidr:  "10.0.0.0/20",
+			valid: true,
+		},
+		{
+			// IPv4 too large
+			name:  "case 1",
+			rules: rules,
+			cidr:  "10.0.0.0/8",
+			valid: false,
+		},
+		{
+			// IPv6 within bounds
+			name:  "case 2",
+			rules: rules,
+			cidr:  "fd00:10::/56",
+			valid: true,
+		},
+		{
+			// IPv6 too small
+			name:  "case 3",
+			rules: rules,
+			cidr:  "fd00:10::/120",
+			valid: false,
+		},
+		{
+			// IPv6 without rule
+			name:  "case 4",
+			rules: ipv4Only,
+			cidr:  "fd00:10::/56",
+			valid: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			network, err := Parse(tc.cidr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = tc.rules.Validate(network)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && !IsPrefixLength(err) {
+				t.Fatalf("expected prefix length error but got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package cidr
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var invalidCIDRError = &microerror.Error{
+	Kind: "invalidCIDRError",
+}
+
+// IsInvalidCIDR asserts invalidCIDRError.
+func IsInvalidCIDR(err error) bool {
+	return microerror.Cause(err) == invalidCIDRError
+}
+
+var mixedFamilyError = &microerror.Error{
+	Kind: "mixedFamilyError",
+}
+
+// IsMixedFamily asserts mixedFamilyError.
+func IsMixedFamily(err error) bool {
+	return microerror.Cause(err) == mixedFamilyError
+}
+
+var prefixLengthError = &microerror.Error{
+	Kind: "prefixLengthError",
+}
+
+// IsPrefixLength asserts prefixLengthError.
+func IsPrefixLength(err error) bool {
+	return microerror.Cause(err) == prefixLengthError
+}
@@ -1,11 +1,17 @@
 package handler
 
 import (
+	"fmt"
+
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
+// AdmissionReviewAPIVersion is the only AdmissionReview version the webhooks answer.
+var AdmissionReviewAPIVersion = admissionv1.SchemeGroupVersion.String()
+
 func ExtractName(request *admissionv1.AdmissionRequest, deserializer runtime.Decoder) string {
 	if request.Name != "" {
 		return request.Name
@@ -24,3 +30,23 @@ func ExtractName(request *admissionv1.AdmissionRequest, deserializer runtime.Dec
 	}
 	return "<unknown>"
 }
+
+// ValidateReviewVersion returns an error if the AdmissionReview is not of the supported version.
+func ValidateReviewVersion(review admissionv1.AdmissionReview) error {
+	if review.APIVersion != AdmissionReviewAPIVersion || review.Kind != "AdmissionReview" {
+		return fmt.Errorf("unsupported admission review %s, Kind=%s, only %s, Kind=AdmissionReview is supported", review.APIVersion, review.Kind, AdmissionReviewAPIVersion)
+	}
+	return nil
+}
+
+// ValidateGroupVersionKind returns an error if the kind of the admission
+// request is none of the accepted ones, e.g. because of a misrouted webhook.
+func ValidateGroupVersionKind(request *admissionv1.AdmissionRequest, accepted []schema.GroupVersionKind) error {
+	gvk := schema.GroupVersionKind(request.Kind)
+	for _, a := range accepted {
+		if gvk == a {
+			return nil
+		}
+	}
+	return fmt.Errorf("admission request for %s is not accepted by this webhook, accepted kinds are %v", gvk, accepted)
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"strconv"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+)
+
+func TestValidateReviewVersion(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+
+		valid bool
+	}{
+		{
+			// v1 AdmissionReview
+			name:  "case 0",
+			data:  `{"apiVersion":"admission.k8s.io/v1","kind":"AdmissionReview","request":{"uid":"1"}}`,
+			valid: true,
+		},
+		{
+			// v1beta1 AdmissionReview
+			name:  "case 1",
+			data:  `{"apiVersion":"admission.k8s.io/v1beta1","kind":"AdmissionReview","request":{"uid":"1"}}`,
+			valid: false,
+		},
+		{
+			// Missing type information
+			name:  "case 2",
+			data:  `{"request":{"uid":"1"}}`,
+			valid: false,
+		},
+	}
+
+	deserializer := serializer.NewCodecFactory(runtime.NewScheme()).UniversalDeserializer()
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			review := admissionv1.AdmissionReview{}
+			_, _, err := deserializer.Decode([]byte(tc.data), nil, &review)
+			if err != nil && tc.valid {
+				t.Fatalf("unexpected decoding error %v", err)
+			}
+			if err != nil {
+				return
+			}
+			err = ValidateReviewVersion(review)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestValidateGroupVersionKind(t *testing.T) {
+	accepted := []schema.GroupVersionKind{
+		{Group: "infrastructure.giantswarm.io", Version: "v1alpha3", Kind: "AWSCluster"},
+	}
+	testCases := []struct {
+		name string
+		kind metav1.GroupVersionKind
+
+		valid bool
+	}{
+		{
+			// Matching kind
+			name:  "case 0",
+			kind:  metav1.GroupVersionKind{Group: "infrastructure.giantswarm.io", Version: "v1alpha3", Kind: "AWSCluster"},
+			valid: true,
+		},
+		{
+			// Different kind
+			name:  "case 1",
+			kind:  metav1.GroupVersionKind{Group: "infrastructure.giantswarm.io", Version: "v1alpha3", Kind: "NetworkPool"},
+			valid: false,
+		},
+		{
+			// Different version
+			name:  "case 2",
+			kind:  metav1.GroupVersionKind{Group: "infrastructure.giantswarm.io", Version: "v1alpha2", Kind: "AWSCluster"},
+			valid: false,
+		},
+		{
+			// Different group
+			name:  "case 3",
+			kind:  metav1.GroupVersionKind{Group: "cluster.x-k8s.io", Version: "v1alpha3", Kind: "AWSCluster"},
+			valid: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := ValidateGroupVersionKind(&admissionv1.AdmissionRequest{Kind: tc.kind}, accepted)
+			if tc.valid && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatalf("expected error but got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"github.com/giantswarm/microerror"
+)
+
+var requestTooLargeError = &microerror.Error{
+	Kind: "requestTooLargeError",
+}
+
+// IsRequestTooLarge asserts requestTooLargeError.
+func IsRequestTooLarge(err error) bool {
+	return microerror.Cause(err) == requestTooLargeError
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/giantswarm/microerror"
+
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/metrics"
+)
+
+// MaxRequestBodyBytes is the largest AdmissionReview body the webhooks read.
+// It matches the request size limit of the Kubernetes API server.
+const MaxRequestBodyBytes = 3 * 1024 * 1024
+
+// ReadBody reads the request body up to MaxRequestBodyBytes and returns a
+// requestTooLargeError if the body exceeds the limit.
+func ReadBody(request *http.Request) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(request.Body, MaxRequestBodyBytes+1))
+	if err != nil {
+		return nil, microerror.Mask(err)
+	}
+	if len(data) > MaxRequestBodyBytes {
+		return nil, microerror.Maskf(requestTooLargeError, "request body exceeds %d bytes", MaxRequestBodyBytes)
+	}
+	return data, nil
+}
+
+// Recover wraps the given handler so that a panic while handling a request is
+// logged and answered with an internal server error instead of tearing down
+// the connection.
+func Recover(log func(keyVals ...interface{}), webhook string, resource string, next http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log("level", "error", "message", fmt.Sprintf("recovered from panic while handling request: %v", r), "stack", string(debug.Stack()))
+				metrics.InternalError.WithLabelValues(webhook, resource).Inc()
+				metrics.RecoveredPanics.WithLabelValues(webhook, resource).Inc()
+				writer.WriteHeader(http.StatusInternalServerError)
+			}
+		}()
+		next(writer, request)
+	}
+}
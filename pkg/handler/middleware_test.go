@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestReadBody(t *testing.T) {
+	testCases := []struct {
+		name string
+		size int
+
+		tooLarge bool
+	}{
+		{
+			// Empty body
+			name:     "case 0",
+			size:     0,
+			tooLarge: false,
+		},
+		{
+			// Body at the limit
+			name:     "case 1",
+			size:     MaxRequestBodyBytes,
+			tooLarge: false,
+		},
+		{
+			// Body exceeding the limit
+			name:     "case 2",
+			size:     MaxRequestBodyBytes + 1,
+			tooLarge: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(make([]byte, tc.size)))
+			data, err := ReadBody(request)
+			if tc.tooLarge && !IsRequestTooLarge(err) {
+				t.Fatalf("expected request too large error but got %v", err)
+			}
+			if !tc.tooLarge && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !tc.tooLarge && len(data) != tc.size {
+				t.Fatalf("expected %d bytes but got %d", tc.size, len(data))
+			}
+		})
+	}
+}
+
+func TestRecover(t *testing.T) {
+	testCases := []struct {
+		name    string
+		handler http.HandlerFunc
+
+		expectedStatus int
+		expectedLogs   int
+	}{
+		{
+			// Handler panics
+			name: "case 0",
+			handler: func(writer http.ResponseWriter, request *http.Request) {
+				var m map[string]string
+				m["boom"] = "boom"
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedLogs:   1,
+		},
+		{
+			// Handler succeeds
+			name: "case 1",
+			handler: func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(http.StatusOK)
+			},
+			expectedStatus: http.StatusOK,
+			expectedLogs:   0,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var logs int
+			log := func(keyVals ...interface{}) { logs++ }
+
+			recorder := httptest.NewRecorder()
+			Recover(log, "validating", "test", tc.handler)(recorder, httptest.NewRequest(http.MethodPost, "/", nil))
+			if recorder.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d but got %d", tc.expectedStatus, recorder.Code)
+			}
+			if logs != tc.expectedLogs {
+				t.Fatalf("expected %d log lines but got %d", tc.expectedLogs, logs)
+			}
+		})
+	}
+}
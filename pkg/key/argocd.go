@@ -0,0 +1,33 @@
+package key
+
+import (
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	argoCDInstanceLabel = "argocd.argoproj.io/instance"
+)
+
+// ArgoCDApplicationObjectKey returns the key of the Argo CD Application which
+// manages the object, or nil if it is not managed by Argo CD. Applications
+// outside of the Argo CD namespace are tracked as `<namespace>_<name>`.
+func ArgoCDApplicationObjectKey(getter LabelsGetter, argoCDNamespace string) *client.ObjectKey {
+	instance := getter.GetLabels()[argoCDInstanceLabel]
+	if instance == "" {
+		return nil
+	}
+
+	if namespace, name, ok := strings.Cut(instance, "_"); ok && namespace != "" && name != "" {
+		return &client.ObjectKey{
+			Name:      name,
+			Namespace: namespace,
+		}
+	}
+
+	return &client.ObjectKey{
+		Name:      instance,
+		Namespace: argoCDNamespace,
+	}
+}
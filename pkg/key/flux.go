@@ -5,15 +5,27 @@ import "sigs.k8s.io/controller-runtime/pkg/client"
 const (
 	fluxKustomizationNameLabel      = "kustomize.toolkit.fluxcd.io/name"
 	fluxKustomizationNamespaceLabel = "kustomize.toolkit.fluxcd.io/namespace"
+	fluxHelmReleaseNameLabel        = "helm.toolkit.fluxcd.io/name"
+	fluxHelmReleaseNamespaceLabel   = "helm.toolkit.fluxcd.io/namespace"
 )
 
 func FluxKustomizationObjectKey(getter LabelsGetter) *client.ObjectKey {
+	return labelsObjectKey(getter, fluxKustomizationNameLabel, fluxKustomizationNamespaceLabel)
+}
+
+// FluxHelmReleaseObjectKey returns the key of the Flux HelmRelease which
+// manages the object, or nil if it is not managed by a HelmRelease.
+func FluxHelmReleaseObjectKey(getter LabelsGetter) *client.ObjectKey {
+	return labelsObjectKey(getter, fluxHelmReleaseNameLabel, fluxHelmReleaseNamespaceLabel)
+}
+
+func labelsObjectKey(getter LabelsGetter, nameLabel string, namespaceLabel string) *client.ObjectKey {
 	fluxName := ""
 	fluxNamespace := ""
 	for k, v := range getter.GetLabels() {
-		if k == fluxKustomizationNameLabel {
+		if k == nameLabel {
 			fluxName = v
-		} else if k == fluxKustomizationNamespaceLabel {
+		} else if k == namespaceLabel {
 			fluxNamespace = v
 		}
 	}
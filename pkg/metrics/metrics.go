@@ -19,6 +19,12 @@ var (
 		Help:      "Duration of request",
 		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, .75, 1, 1.25, 1.5, 2, 2.5, 5, 10},
 	}, labels)
+	GitopsBypasses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "gitops_bypasses_total",
+		Help:      "Total number of out-of-band edits of GitOps-managed objects allowed by the bypass annotation",
+	}, []string{"kind"})
 	InternalError = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: metricNamespace,
 		Subsystem: metricSubsystem,
@@ -31,6 +37,18 @@ var (
 		Name:      "requests_invalid_total",
 		Help:      "Total number of invalid requests",
 	}, labels)
+	MisroutedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "requests_misrouted_total",
+		Help:      "Total number of requests for a resource kind not served by the webhook",
+	}, labels)
+	RecoveredPanics = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricNamespace,
+		Subsystem: metricSubsystem,
+		Name:      "panics_recovered_total",
+		Help:      "Total number of panics recovered while handling requests",
+	}, labels)
 	RejectedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: metricNamespace,
 		Subsystem: metricSubsystem,
@@ -52,5 +70,5 @@ var (
 )
 
 func init() {
-	prometheus.MustRegister(TotalRequests, InvalidRequests, RejectedRequests, SuccessfulRequests, DurationRequests)
+	prometheus.MustRegister(TotalRequests, GitopsBypasses, InternalError, InvalidRequests, MisroutedRequests, RecoveredPanics, RejectedRequests, SuccessfulRequests, DurationRequests)
 }
@@ -1,37 +1,18 @@
 package mutator
 
+import (
+	"github.com/giantswarm/aws-admission-controller/v4/pkg/admission"
+)
+
 // PatchOperation specifies one JSONPatch operation.
-// See [RFC6902](https://tools.ietf.org/html/rfc6902) for details.
-type PatchOperation struct {
-	Operation string      `json:"op"`
-	Path      string      `json:"path"`
-	Value     interface{} `json:"value"`
-}
+type PatchOperation = admission.PatchOperation
 
 // PatchReplace creates a patch operation of type "replace".
 func PatchReplace(path string, value interface{}) PatchOperation {
-	return PatchOperation{
-		Operation: "replace",
-		Path:      path,
-		Value:     value,
-	}
+	return admission.PatchReplace(path, value)
 }
 
 // PatchAdd creates a patch operation of type "add".
-//
-// The "add" operation performs one of the following functions,
-// depending upon what the target location references:
-//
-//   - If the target location specifies an array index, a new value is
-//     inserted into the array at the specified index.
-//   - If the target location specifies an object member that does not
-//     already exist, a new member is added to the object.
-//   - If the target location specifies an object member that does exist,
-//     that member's value is replaced.
 func PatchAdd(path string, value interface{}) PatchOperation {
-	return PatchOperation{
-		Operation: "add",
-		Path:      path,
-		Value:     value,
-	}
+	return admission.PatchAdd(path, value)
 }
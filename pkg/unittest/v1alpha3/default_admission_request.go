@@ -105,3 +105,43 @@ func DefaultAdmissionRequestAWSMachineDeployment() (admissionv1.AdmissionRequest
 	}
 	return req, nil
 }
+
+func DeleteAdmissionRequest(obj interface{}, kind metav1.GroupVersionKind) (admissionv1.AdmissionRequest, error) {
+	byt, err := json.Marshal(obj)
+	if err != nil {
+		return admissionv1.AdmissionRequest{}, microerror.Mask(err)
+	}
+	req := admissionv1.AdmissionRequest{
+		Kind:      kind,
+		Operation: admissionv1.Delete,
+		OldObject: runtime.RawExtension{
+			Raw:    byt,
+			Object: nil,
+		},
+	}
+	return req, nil
+}
+
+func UpdateAdmissionRequest(oldObj interface{}, obj interface{}, kind metav1.GroupVersionKind) (admissionv1.AdmissionRequest, error) {
+	oldByt, err := json.Marshal(oldObj)
+	if err != nil {
+		return admissionv1.AdmissionRequest{}, microerror.Mask(err)
+	}
+	byt, err := json.Marshal(obj)
+	if err != nil {
+		return admissionv1.AdmissionRequest{}, microerror.Mask(err)
+	}
+	req := admissionv1.AdmissionRequest{
+		Kind:      kind,
+		Operation: admissionv1.Update,
+		Object: runtime.RawExtension{
+			Raw:    byt,
+			Object: nil,
+		},
+		OldObject: runtime.RawExtension{
+			Raw:    oldByt,
+			Object: nil,
+		},
+	}
+	return req, nil
+}
@@ -62,6 +62,15 @@ func FakeK8sClient() k8sclient.Interface {
 	return k8sClient
 }
 
+// FailingK8sClient returns a client whose requests fail with errors other
+// than NotFound, as if the API server was unavailable.
+func FailingK8sClient() k8sclient.Interface {
+	return &fakeK8sClient{
+		ctrlClient: fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build(),
+		k8sClient:  fakek8s.NewSimpleClientset(),
+	}
+}
+
 func (f *fakeK8sClient) CRDClient() k8scrdclient.Interface {
 	return nil
 }